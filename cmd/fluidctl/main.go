@@ -1,14 +1,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/fluidstackio/fluidctl/internal/auth"
 	"github.com/fluidstackio/fluidctl/internal/filesystem"
+	"github.com/fluidstackio/fluidctl/internal/image"
 	"github.com/fluidstackio/fluidctl/internal/instance"
+	"github.com/fluidstackio/fluidctl/internal/instancetype"
 	"github.com/fluidstackio/fluidctl/internal/kubernetes"
 	"github.com/fluidstackio/fluidctl/internal/project"
 	"github.com/fluidstackio/fluidctl/internal/slurm"
+	"github.com/fluidstackio/fluidctl/internal/ui"
+	"github.com/fluidstackio/fluidctl/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -26,17 +32,21 @@ func rootCommand() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringP("url", "U", "https://atlas.fluidstack.io", "Atlas Server URL")
-	cmd.PersistentFlags().StringP("format", "F", "yaml", "Output format (json, yaml)")
+	cmd.PersistentFlags().StringP("format", "F", "yaml", "Output format (json, yaml, table)")
 	cmd.PersistentFlags().StringP("token", "T", "", "Auth token")
 	cmd.PersistentFlags().String("client-id", "", "OAuth Client ID")
 	cmd.PersistentFlags().String("client-secret", "", "OAuth Client Secret")
 
 	cmd.AddCommand(
+		auth.LoginCommand(),
 		instance.Command(),
 		project.Command(),
 		filesystem.Command(),
 		slurm.Command(),
 		kubernetes.Command(),
+		image.Command(),
+		instancetype.Command(),
+		ui.Command(),
 	)
 
 	return &cmd
@@ -44,6 +54,11 @@ func rootCommand() *cobra.Command {
 
 func main() {
 	if err := rootCommand().Execute(); err != nil {
+		var exitErr *utils.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+
 		fmt.Println(err)
 
 		os.Exit(1)
@@ -32,6 +32,9 @@ var (
 	defaultAudience  = "https://api.fluidstack.io"
 	defaultRedirect  = "http://localhost:5173"
 	defaultTokenFile = "~/.fluidstack/token"
+	// defaultRefreshTokenFile holds the refresh token of the browser login,
+	// used to get a new access token without user interaction.
+	defaultRefreshTokenFile = "~/.fluidstack/refresh_token"
 )
 
 func fetchTokenFromClientCredentials(clientID string, clientSecret string) (*oauth2.Token, error) {
@@ -48,30 +51,45 @@ func fetchTokenFromClientCredentials(clientID string, clientSecret string) (*oau
 	return config.TokenSource(context.Background()).Token()
 }
 
-func isTokenExpired(tokenString string) (bool, error) {
+// TokenExpiry returns the expiration time of a token.
+func TokenExpiry(tokenString string) (time.Time, error) {
 	token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
 	if err != nil {
-		return false, fmt.Errorf("failed to parse token: %w", err)
+		return time.Time{}, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if exp, ok := claims["exp"].(float64); ok {
-			expirationTime := time.Unix(int64(exp), 0)
-			if time.Now().After(expirationTime) {
-				return true, nil
-			} else {
-				return false, nil
-			}
-		} else {
-			return false, errors.New("token does not contain an expiration claim")
-		}
-	} else {
-		return false, errors.New("invalid token claims")
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return time.Time{}, errors.New("invalid token claims")
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}, errors.New("token does not contain an expiration claim")
 	}
+
+	return time.Unix(int64(exp), 0), nil
+}
+
+func isTokenExpired(tokenString string) (bool, error) {
+	expirationTime, err := TokenExpiry(tokenString)
+	if err != nil {
+		return false, err
+	}
+
+	return time.Now().After(expirationTime), nil
 }
 
 func readToken() (string, error) {
-	tokenFile, err := homedir.Expand(defaultTokenFile)
+	return readTokenFile(defaultTokenFile)
+}
+
+func writeToken(token string) error {
+	return writeTokenFile(defaultTokenFile, token)
+}
+
+func readTokenFile(path string) (string, error) {
+	tokenFile, err := homedir.Expand(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to expand token file path: %w", err)
 	}
@@ -84,8 +102,8 @@ func readToken() (string, error) {
 	return strings.TrimSpace(string(token)), nil
 }
 
-func writeToken(token string) error {
-	tokenFile, err := homedir.Expand(defaultTokenFile)
+func writeTokenFile(path string, token string) error {
+	tokenFile, err := homedir.Expand(path)
 	if err != nil {
 		return fmt.Errorf("failed to expand token file path: %w", err)
 	}
@@ -103,6 +121,58 @@ func writeToken(token string) error {
 	return nil
 }
 
+// saveToken stores the access token and, if the token endpoint returned one,
+// the refresh token.
+func saveToken(token *oauth2.Token) error {
+	if err := writeToken(token.AccessToken); err != nil {
+		return err
+	}
+
+	if token.RefreshToken != "" {
+		if err := writeTokenFile(defaultRefreshTokenFile, token.RefreshToken); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func oauthConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:    defaultClientID,
+		RedirectURL: defaultRedirect,
+		Scopes:      []string{"openid", "profile", "email", "offline_access"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  defaultAuthURL,
+			TokenURL: defaultTokenURL,
+		},
+	}
+}
+
+// refreshToken gets a new access token with the saved refresh token, without
+// user interaction.
+func refreshToken(ctx context.Context) (string, error) {
+	refreshToken, err := readTokenFile(defaultRefreshTokenFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errLoginRequired
+		}
+
+		return "", fmt.Errorf("failed to read refresh token file: %w", err)
+	}
+
+	token, err := oauthConfig().TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to refresh token: %v", errLoginRequired, err)
+	}
+
+	if err := saveToken(token); err != nil {
+		return "", fmt.Errorf("failed to save token: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
+
 func randomBytesInHex(count int) (string, error) {
 	buf := make([]byte, count)
 	_, err := io.ReadFull(rand.Reader, buf)
@@ -119,7 +189,12 @@ func GenerateCodeChallenge(verifier string) string {
 	return base64.RawURLEncoding.EncodeToString(hash[:])
 }
 
-func Login(cmd *cobra.Command) (string, error) {
+var errLoginRequired = errors.New("login required")
+
+// CachedLogin returns a token without user interaction, from client
+// credentials, the token flag, the token file or by refreshing an expired
+// token. It fails if the browser login flow would be needed.
+func CachedLogin(cmd *cobra.Command) (string, error) {
 	clientID := utils.MustGetStringFlag(cmd, "client-id")
 	clientSecret := utils.MustGetStringFlag(cmd, "client-secret")
 	if clientID != "" && clientSecret != "" {
@@ -145,6 +220,23 @@ func Login(cmd *cobra.Command) (string, error) {
 		return string(tokenString), nil
 	}
 
+	return refreshToken(cmd.Context())
+}
+
+// Login returns a cached token if there is one and otherwise logs in through
+// the browser.
+func Login(cmd *cobra.Command) (string, error) {
+	tokenString, err := CachedLogin(cmd)
+	if !errors.Is(err, errLoginRequired) {
+		return tokenString, err
+	}
+
+	return browserLogin(cmd)
+}
+
+// browserLogin runs the authorization code flow in the browser and saves the
+// tokens.
+func browserLogin(cmd *cobra.Command) (string, error) {
 	codeVerifier, verifierErr := randomBytesInHex(32)
 	if verifierErr != nil {
 		return "", fmt.Errorf("failed to create code verifier: %v", verifierErr)
@@ -154,15 +246,7 @@ func Login(cmd *cobra.Command) (string, error) {
 	hash.Write([]byte(codeVerifier))
 	codeChallenge := base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
 
-	config := &oauth2.Config{
-		ClientID:    defaultClientID,
-		RedirectURL: defaultRedirect,
-		Scopes:      []string{"openid", "profile", "email", "offline_access"},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  defaultAuthURL,
-			TokenURL: defaultTokenURL,
-		},
-	}
+	config := oauthConfig()
 
 	state, stateErr := randomBytesInHex(24)
 	if stateErr != nil {
@@ -186,13 +270,31 @@ func Login(cmd *cobra.Command) (string, error) {
 		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
 	}
 
-	if err := writeToken(token.AccessToken); err != nil {
+	if err := saveToken(token); err != nil {
 		return "", fmt.Errorf("failed to save token: %w", err)
 	}
 
 	return token.AccessToken, nil
 }
 
+func LoginCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "login",
+		Short: "Log in through the browser",
+		Long:  "Log in through the browser and save the token for other commands. The token is refreshed automatically when it expires, so this is only needed once, or when the refresh fails.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := browserLogin(cmd); err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+
+			fmt.Println("Logged in")
+
+			return nil
+		},
+	}
+}
+
 // waitForAuthorizationCode starts a local server to capture the authorization code.
 func waitForAuthorizationCode(authURL string) (string, error) {
 	server := &http.Server{Addr: ":5173"}
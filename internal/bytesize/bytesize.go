@@ -0,0 +1,104 @@
+package bytesize
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	B  int64 = 1
+	Ki       = 1 << 10
+	Mi       = 1 << 20
+	Gi       = 1 << 30
+	Ti       = 1 << 40
+	Pi       = 1 << 50
+
+	K int64 = 1000
+	M       = K * 1000
+	G       = M * 1000
+	T       = G * 1000
+	P       = T * 1000
+)
+
+var units = map[string]int64{
+	"":   B,
+	"B":  B,
+	"Ki": Ki, "KiB": Ki,
+	"Mi": Mi, "MiB": Mi,
+	"Gi": Gi, "GiB": Gi,
+	"Ti": Ti, "TiB": Ti,
+	"Pi": Pi, "PiB": Pi,
+	"k": K, "K": K, "KB": K,
+	"M": M, "MB": M,
+	"G": G, "GB": G,
+	"T": T, "TB": T,
+	"P": P, "PB": P,
+}
+
+var binaryUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"Pi", Pi},
+	{"Ti", Ti},
+	{"Gi", Gi},
+	{"Mi", Mi},
+	{"Ki", Ki},
+}
+
+// Parse parses a size such as 4Ti, 1.5TiB, 500G or 1024 (bytes) into bytes.
+// Binary (Ki, Mi, Gi, Ti, Pi) and decimal (k, M, G, T, P) units are accepted,
+// optionally followed by B.
+func Parse(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	number, unit := s[:i], strings.TrimSpace(s[i:])
+	if number == "" {
+		return 0, fmt.Errorf("invalid size %q: missing number", s)
+	}
+
+	multiplier, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q (use Ki, Mi, Gi, Ti, Pi or k, M, G, T, P)", s, unit)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit in an
+	// int64, so the comparison has to include it.
+	bytes := value * float64(multiplier)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+
+	if bytes != math.Trunc(bytes) {
+		return 0, fmt.Errorf("invalid size %q: not a whole number of bytes", s)
+	}
+
+	return int64(bytes), nil
+}
+
+// Format formats bytes using the largest binary unit that keeps the value at
+// or above one, with at most one decimal, e.g. 1Ti or 1.5Ti.
+func Format(bytes int64) string {
+	for _, u := range binaryUnits {
+		if bytes >= u.size {
+			value := strconv.FormatFloat(float64(bytes)/float64(u.size), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + u.suffix
+		}
+	}
+
+	return strconv.FormatInt(bytes, 10)
+}
@@ -0,0 +1,79 @@
+package bytesize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "1024", want: 1024},
+		{in: "0", want: 0},
+		{in: "1B", want: 1},
+		{in: "4Ti", want: 4 * Ti},
+		{in: "4TiB", want: 4 * Ti},
+		{in: "1.5Ti", want: Ti + Ti/2},
+		{in: "500G", want: 500 * G},
+		{in: "500GB", want: 500 * G},
+		{in: "2k", want: 2000},
+		{in: "2K", want: 2000},
+		{in: " 10 Gi ", want: 10 * Gi},
+		{in: "8191Pi", want: 8191 * Pi},
+		{in: "", wantErr: true},
+		{in: "Gi", wantErr: true},
+		{in: "10Xi", wantErr: true},
+		{in: "10gi", wantErr: true},
+		{in: "1.2.3Gi", wantErr: true},
+		{in: "0.5", wantErr: true},
+		{in: "-1Gi", wantErr: true},
+		{in: "1e30Ti", wantErr: true},
+		{in: "8192Pi", wantErr: true},
+		{in: "9223372036854775808", wantErr: true},
+		{in: "1000000000000000000000000000000Ti", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %d, expected error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{in: 0, want: "0"},
+		{in: 1023, want: "1023"},
+		{in: Ki, want: "1Ki"},
+		{in: 100 * Gi, want: "100Gi"},
+		{in: Ti + Ti/2, want: "1.5Ti"},
+		{in: Ti + Ti/100, want: "1Ti"},
+		{in: 4096 * Pi, want: "4096Pi"},
+		{in: math.MaxInt64, want: "8192Pi"},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.in); got != tt.want {
+			t.Errorf("Format(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
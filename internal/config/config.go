@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+var defaultConfigFile = "~/.fluidstack/config.yaml"
+
+// Config is local client state that has no home in the Atlas API.
+type Config struct {
+	Projects  map[string]*Project `yaml:"projects,omitempty"`
+	Templates map[string]Template `yaml:"templates,omitempty"`
+}
+
+type Project struct {
+	SSHKeys []SSHKey `yaml:"ssh_keys,omitempty"`
+}
+
+type SSHKey struct {
+	Name string `yaml:"name" json:"name"`
+	Key  string `yaml:"key" json:"key"`
+}
+
+// Template holds saved 'instances create' flag values, keyed by flag name.
+// Values are strings, or lists of strings for repeatable flags.
+type Template map[string]interface{}
+
+// Load reads the config file. A missing file yields an empty config.
+func Load() (*Config, error) {
+	configFile, err := homedir.Expand(defaultConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand config file path: %w", err)
+	}
+
+	cfg := &Config{}
+
+	b, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) Save() error {
+	configFile, err := homedir.Expand(defaultConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to expand config file path: %w", err)
+	}
+
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(configFile), 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create config file directory: %w", err)
+	}
+
+	err = os.WriteFile(configFile, b, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// Project returns the settings for the project, creating them if needed.
+func (c *Config) Project(id uuid.UUID) *Project {
+	if c.Projects == nil {
+		c.Projects = map[string]*Project{}
+	}
+
+	p, ok := c.Projects[id.String()]
+	if !ok {
+		p = &Project{}
+		c.Projects[id.String()] = p
+	}
+
+	return p
+}
@@ -0,0 +1,166 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+
+	atlas "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Attachment is a filesystem given on the command line to attach to an
+// instance or cluster.
+type Attachment struct {
+	ID        uuid.UUID
+	Name      string
+	MountPath string
+	ReadOnly  bool
+}
+
+// ParseAttachment parses a filesystem in the format
+// 'id=<UUID>|name=<name>[,mount=<path>][,readonly=true]'. Names are resolved
+// to IDs by ResolveAttachments.
+func ParseAttachment(s string) (Attachment, error) {
+	attachment := Attachment{}
+
+	for k, v := range utils.ParseAttrs(s) {
+		switch k {
+		case "id":
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return attachment, fmt.Errorf("invalid filesystem id: %s", v)
+			}
+			attachment.ID = id
+		case "name":
+			if v == "" {
+				return attachment, fmt.Errorf("empty filesystem name: %s", s)
+			}
+			attachment.Name = v
+		case "mount":
+			if !path.IsAbs(v) {
+				return attachment, fmt.Errorf("invalid mount path %q in filesystem: must be absolute", v)
+			}
+			attachment.MountPath = path.Clean(v)
+		case "readonly":
+			if v == "" {
+				attachment.ReadOnly = true
+				continue
+			}
+
+			readOnly, err := strconv.ParseBool(v)
+			if err != nil {
+				return attachment, fmt.Errorf("invalid readonly value %q in filesystem: %s", v, s)
+			}
+			attachment.ReadOnly = readOnly
+		default:
+			return attachment, fmt.Errorf("unknown attribute %q in filesystem: %s", k, s)
+		}
+	}
+
+	if attachment.ID == uuid.Nil && attachment.Name == "" {
+		return attachment, fmt.Errorf("missing 'id' or 'name' attribute in filesystem: %s", s)
+	}
+
+	if attachment.ID != uuid.Nil && attachment.Name != "" {
+		return attachment, fmt.Errorf("only one of 'id' and 'name' may be given in filesystem: %s", s)
+	}
+
+	return attachment, nil
+}
+
+// ValidateAttachments checks that no filesystem is given twice and that no two
+// filesystems share a mount path. Attachments given by name must be resolved
+// first.
+func ValidateAttachments(attachments []Attachment) error {
+	ids := map[uuid.UUID]bool{}
+	mountPaths := map[string]bool{}
+
+	for _, attachment := range attachments {
+		if ids[attachment.ID] {
+			return fmt.Errorf("filesystem %s is given more than once", attachment.ID)
+		}
+		ids[attachment.ID] = true
+
+		if attachment.MountPath == "" {
+			continue
+		}
+
+		if mountPaths[attachment.MountPath] {
+			return fmt.Errorf("mount path %s is used by more than one filesystem", attachment.MountPath)
+		}
+		mountPaths[attachment.MountPath] = true
+	}
+
+	return nil
+}
+
+// MountEntry returns the cloud-config mounts entry for the attachment.
+// Filesystems attached to an instance are exposed as virtiofs devices tagged
+// with the filesystem ID.
+func (a Attachment) MountEntry() []string {
+	options := "defaults,nofail"
+	if a.ReadOnly {
+		options += ",ro"
+	}
+
+	return []string{a.ID.String(), a.MountPath, "virtiofs", options, "0", "0"}
+}
+
+// Request returns the API request attaching the filesystem to an instance.
+func (a Attachment) Request() atlas.InstancesFilesystemsPostRequest {
+	req := atlas.InstancesFilesystemsPostRequest{
+		FilesystemId: a.ID,
+		ReadOnly:     &a.ReadOnly,
+	}
+	if a.MountPath != "" {
+		req.MountPath = &a.MountPath
+	}
+
+	return req
+}
+
+// ResolveAttachments looks up the IDs of attachments given by name.
+func ResolveAttachments(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, attachments []Attachment) error {
+	if !slices.ContainsFunc(attachments, func(a Attachment) bool { return a.ID == uuid.Nil }) {
+		return nil
+	}
+
+	res, err := c.GetFilesystemsWithResponse(ctx, &atlas.GetFilesystemsParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return fmt.Errorf("failed to list filesystems: %s", res.Status())
+	}
+
+	for i, attachment := range attachments {
+		if attachment.ID != uuid.Nil {
+			continue
+		}
+
+		for _, fs := range *res.JSON200 {
+			if fs.Name != attachment.Name {
+				continue
+			}
+
+			if attachments[i].ID != uuid.Nil {
+				return fmt.Errorf("filesystem name %q is ambiguous, use 'id=' instead", attachment.Name)
+			}
+			attachments[i].ID = fs.Id
+		}
+
+		if attachments[i].ID == uuid.Nil {
+			return fmt.Errorf("filesystem not found: %s", attachment.Name)
+		}
+	}
+
+	return nil
+}
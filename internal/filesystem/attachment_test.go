@@ -0,0 +1,109 @@
+package filesystem
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseAttachment(t *testing.T) {
+	id := uuid.MustParse("0b8f6e0c-2f4e-4d39-9a4a-55a6d9c3f1e2")
+
+	tests := []struct {
+		name    string
+		in      string
+		want    Attachment
+		wantErr bool
+	}{
+		{name: "id", in: "id=" + id.String(), want: Attachment{ID: id}},
+		{name: "name", in: "name=datasets", want: Attachment{Name: "datasets"}},
+		{name: "mount path", in: "name=datasets,mount=/mnt/data", want: Attachment{Name: "datasets", MountPath: "/mnt/data"}},
+		{name: "mount path is cleaned", in: "name=datasets,mount=/mnt/data/", want: Attachment{Name: "datasets", MountPath: "/mnt/data"}},
+		{name: "bare readonly", in: "name=datasets,readonly", want: Attachment{Name: "datasets", ReadOnly: true}},
+		{name: "readonly true", in: "name=datasets,readonly=true", want: Attachment{Name: "datasets", ReadOnly: true}},
+		{name: "readonly false", in: "name=datasets,readonly=false", want: Attachment{Name: "datasets"}},
+		{name: "all attributes", in: "id=" + id.String() + ",mount=/data,readonly=1", want: Attachment{ID: id, MountPath: "/data", ReadOnly: true}},
+		{name: "empty", in: "", wantErr: true},
+		{name: "missing id and name", in: "mount=/mnt/data", wantErr: true},
+		{name: "invalid id", in: "id=not-a-uuid", wantErr: true},
+		{name: "empty name", in: "name=", wantErr: true},
+		{name: "id and name", in: "name=datasets,id=" + id.String(), wantErr: true},
+		{name: "relative mount path", in: "name=datasets,mount=mnt/data", wantErr: true},
+		{name: "invalid readonly", in: "name=datasets,readonly=maybe", wantErr: true},
+		{name: "unknown attribute", in: "name=datasets,size=1Ti", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttachment(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseAttachment(%q) = %+v, expected error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAttachment(%q) error: %v", tt.in, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseAttachment(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAttachments(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		attachments []Attachment
+		wantErr     bool
+	}{
+		{name: "none"},
+		{name: "distinct", attachments: []Attachment{{ID: a, MountPath: "/a"}, {ID: b, MountPath: "/b"}}},
+		{name: "unmounted", attachments: []Attachment{{ID: a}, {ID: b, ReadOnly: true}}},
+		{name: "same filesystem twice", attachments: []Attachment{{ID: a, MountPath: "/a"}, {ID: a, MountPath: "/b"}}, wantErr: true},
+		{name: "shared mount path", attachments: []Attachment{{ID: a, MountPath: "/data"}, {ID: b, MountPath: "/data"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAttachments(tt.attachments)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAttachments() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMountEntry(t *testing.T) {
+	id := uuid.MustParse("0b8f6e0c-2f4e-4d39-9a4a-55a6d9c3f1e2")
+
+	tests := []struct {
+		name       string
+		attachment Attachment
+		want       []string
+	}{
+		{
+			name:       "read-write",
+			attachment: Attachment{ID: id, MountPath: "/mnt/data"},
+			want:       []string{id.String(), "/mnt/data", "virtiofs", "defaults,nofail", "0", "0"},
+		},
+		{
+			name:       "read-only",
+			attachment: Attachment{ID: id, MountPath: "/mnt/data", ReadOnly: true},
+			want:       []string{id.String(), "/mnt/data", "virtiofs", "defaults,nofail,ro", "0", "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.attachment.MountEntry(); !slices.Equal(got, tt.want) {
+				t.Errorf("MountEntry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package filesystem
 import (
 	"fmt"
 	"net/http"
+	"slices"
 
 	atlas "github.com/fluidstackio/atlas-client-go/v1alpha1"
 	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/bytesize"
 	"github.com/fluidstackio/fluidctl/internal/format"
 	"github.com/fluidstackio/fluidctl/internal/utils"
 	"github.com/google/uuid"
@@ -29,6 +31,10 @@ func Command() *cobra.Command {
 		DeleteCommand(),
 		ListCommand(),
 		DescribeCommand(),
+		ResizeCommand(),
+		SyncCommand(),
+		SnapshotCommand(),
+		CloneCommand(),
 	)
 
 	return cmd
@@ -41,7 +47,11 @@ func CreateCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			url := utils.MustGetStringFlag(cmd, "url")
 			name := utils.MustGetStringFlag(cmd, "name")
-			size := utils.MustGetStringFlag(cmd, "size")
+
+			_, size, err := parseSize(utils.MustGetStringFlag(cmd, "size"))
+			if err != nil {
+				return err
+			}
 
 			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
 			if err != nil {
@@ -81,7 +91,7 @@ func CreateCommand() *cobra.Command {
 	}
 
 	cmd.Flags().String("name", "", "Name of the filesystem")
-	cmd.Flags().String("size", "1024Gi", "Size of the filesystem in GiB")
+	cmd.Flags().String("size", "1024Gi", "Size of the filesystem, from 100Gi to 1Pi (e.g. 1024Gi, 4Ti)")
 
 	return cmd
 }
@@ -170,7 +180,7 @@ func ListCommand() *cobra.Command {
 				return err
 			}
 
-			if res.StatusCode() != http.StatusOK {
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
 				return fmt.Errorf("failed to list filesystems: %s", res.Status())
 			}
 
@@ -180,7 +190,7 @@ func ListCommand() *cobra.Command {
 				return err
 			}
 
-			b, err := m.Marshal(res.JSON200)
+			b, err := m.Marshal(filesystemTable(*res.JSON200))
 			if err != nil {
 				return err
 			}
@@ -229,17 +239,33 @@ func DescribeCommand() *cobra.Command {
 				return err
 			}
 
-			if res.StatusCode() != http.StatusOK {
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
 				return fmt.Errorf("failed to get filesystem: %s", res.Status())
 			}
 
+			instances, err := c.GetInstancesWithResponse(cmd.Context(), &atlas.GetInstancesParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if instances.StatusCode() != http.StatusOK || instances.JSON200 == nil {
+				return fmt.Errorf("failed to list instances: %s", instances.Status())
+			}
+
+			details := filesystemDetails{
+				Filesystem: *res.JSON200,
+				Instances:  attachedInstances(*instances.JSON200, id),
+			}
+
 			f := utils.MustGetStringFlag(cmd, "format")
 			m, err := format.NewMarshaller(format.Format(f))
 			if err != nil {
 				return err
 			}
 
-			b, err := m.Marshal(res.JSON200)
+			b, err := m.Marshal(details)
 			if err != nil {
 				return err
 			}
@@ -254,3 +280,115 @@ func DescribeCommand() *cobra.Command {
 
 	return cmd
 }
+
+func ResizeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resize <id>",
+		Short: "Grow a filesystem",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			newSize, size, err := parseSize(utils.MustGetStringFlag(cmd, "size"))
+			if err != nil {
+				return err
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			current, err := c.GetFilesystemsIdWithResponse(cmd.Context(), id, &atlas.GetFilesystemsIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if current.StatusCode() != http.StatusOK || current.JSON200 == nil {
+				return fmt.Errorf("failed to get filesystem: %s", current.Status())
+			}
+
+			if currentSize, err := bytesize.Parse(current.JSON200.Size); err == nil {
+				if newSize < currentSize {
+					return fmt.Errorf("cannot shrink filesystem from %s to %s", bytesize.Format(currentSize), bytesize.Format(newSize))
+				}
+
+				if newSize == currentSize {
+					return fmt.Errorf("filesystem is already %s", bytesize.Format(currentSize))
+				}
+			}
+
+			res, err := c.PatchFilesystemsIdWithResponse(cmd.Context(), id, &atlas.PatchFilesystemsIdParams{
+				XPROJECTID: projectID,
+			}, atlas.FilesystemsPatchRequest{
+				Size: &size,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusOK {
+				return fmt.Errorf("failed to resize filesystem: %s", res.Status())
+			}
+
+			fmt.Printf("Resizing filesystem with ID: %s to %s\n", id, bytesize.Format(newSize))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("size", "", "New size of the filesystem, from 100Gi to 1Pi (e.g. 4Ti)")
+	cmd.MarkFlagRequired("size")
+
+	return cmd
+}
+
+type attachedInstance struct {
+	ID    uuid.UUID           `json:"id" yaml:"id"`
+	Name  string              `json:"name" yaml:"name"`
+	State atlas.InstanceState `json:"state" yaml:"state"`
+}
+
+type filesystemDetails struct {
+	atlas.Filesystem `yaml:",inline"`
+	Instances        []attachedInstance `json:"instances" yaml:"instances"`
+}
+
+// attachedInstances returns the instances that have the filesystem attached.
+func attachedInstances(instances []atlas.Instance, id uuid.UUID) []attachedInstance {
+	attached := []attachedInstance{}
+	for _, instance := range instances {
+		if instance.Filesystems == nil || !slices.Contains(*instance.Filesystems, id) {
+			continue
+		}
+
+		attached = append(attached, attachedInstance{
+			ID:    instance.Id,
+			Name:  instance.Name,
+			State: instance.State,
+		})
+	}
+
+	return attached
+}
@@ -0,0 +1,72 @@
+package filesystem
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	atlas "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/bytesize"
+)
+
+const (
+	minSize = 100 * bytesize.Gi
+	maxSize = 1 * bytesize.Pi
+)
+
+// parseSize validates a filesystem size and returns it in the whole-GiB form
+// the API expects, e.g. "4096Gi" for "4Ti". Sizes that are not a multiple of
+// 1Gi, such as decimal units, are rounded up with a warning, as the rounded
+// size is the one billed.
+func parseSize(s string) (int64, string, error) {
+	size, err := bytesize.Parse(s)
+	if err != nil {
+		return 0, "", err
+	}
+
+	rounded := size
+	if rem := size % bytesize.Gi; rem != 0 && size < math.MaxInt64-bytesize.Gi {
+		rounded += bytesize.Gi - rem
+	}
+
+	if rounded < minSize {
+		return 0, "", fmt.Errorf("invalid size %q: must be at least %s", s, bytesize.Format(minSize))
+	}
+
+	if rounded > maxSize {
+		return 0, "", fmt.Errorf("invalid size %q: must be at most %s", s, bytesize.Format(maxSize))
+	}
+
+	apiSize := fmt.Sprintf("%dGi", rounded/bytesize.Gi)
+	if rounded != size {
+		fmt.Fprintf(os.Stderr, "Warning: size %s is not a whole number of GiB, rounding up to %s\n", s, apiSize)
+	}
+
+	return rounded, apiSize, nil
+}
+
+// humanSize formats a size reported by the API in human units, falling back
+// to the raw value if it cannot be parsed.
+func humanSize(s string) string {
+	size, err := bytesize.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	return bytesize.Format(size)
+}
+
+type filesystemTable []atlas.Filesystem
+
+func (t filesystemTable) Header() []string {
+	return []string{"NAME", "ID", "SIZE", "STATE", "CREATED"}
+}
+
+func (t filesystemTable) Rows() [][]string {
+	rows := [][]string{}
+	for _, fs := range t {
+		rows = append(rows, []string{fs.Name, fs.Id.String(), humanSize(fs.Size), string(fs.State), fs.CreatedAt.Format("2006-01-02 15:04")})
+	}
+
+	return rows
+}
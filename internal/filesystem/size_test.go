@@ -0,0 +1,45 @@
+package filesystem
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "binary unit", in: "4Ti", want: "4096Gi"},
+		{name: "whole GiB", in: "250Gi", want: "250Gi"},
+		{name: "minimum", in: "100Gi", want: "100Gi"},
+		{name: "maximum", in: "1Pi", want: "1048576Gi"},
+		{name: "decimal unit is rounded up", in: "500G", want: "466Gi"},
+		{name: "fraction of a GiB is rounded up", in: "100.5Gi", want: "101Gi"},
+		{name: "below minimum", in: "99Gi", wantErr: true},
+		{name: "rounded up to the minimum", in: "99.5Gi", want: "100Gi"},
+		{name: "bytes", in: "1", wantErr: true},
+		{name: "above maximum", in: "1025Ti", wantErr: true},
+		{name: "zero", in: "0", wantErr: true},
+		{name: "not a size", in: "lots", wantErr: true},
+		{name: "largest int", in: "9223372036854775807", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := parseSize(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSize(%q) = %q, expected error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
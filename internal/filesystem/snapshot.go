@@ -0,0 +1,541 @@
+package filesystem
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	atlas "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/bytesize"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/fluidstackio/fluidctl/internal/wait"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+const (
+	filesystemReady atlas.FilesystemState = "ready"
+	filesystemError atlas.FilesystemState = "error"
+
+	snapshotReady atlas.FilesystemSnapshotState = "ready"
+	snapshotError atlas.FilesystemSnapshotState = "error"
+)
+
+func SnapshotCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "snapshot",
+		Aliases: []string{"snapshots"},
+		Short:   "Manage filesystem snapshots",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		SnapshotCreateCommand(),
+		SnapshotListCommand(),
+		SnapshotDeleteCommand(),
+		SnapshotRestoreCommand(),
+	)
+
+	return cmd
+}
+
+func SnapshotCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <filesystem>",
+		Short: "Snapshot a filesystem",
+		Long:  "Snapshot a filesystem, given by name or ID.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			name := utils.MustGetStringFlag(cmd, "name")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			fs, err := lookup(cmd.Context(), c, projectID, args[0])
+			if err != nil {
+				return err
+			}
+
+			if name == "" {
+				name = fs.Name + "-" + time.Now().UTC().Format("20060102-150405")
+			}
+
+			res, err := c.PostFilesystemsIdSnapshotsWithResponse(cmd.Context(), fs.Id, &atlas.PostFilesystemsIdSnapshotsParams{
+				XPROJECTID: projectID,
+			}, atlas.FilesystemSnapshotsPostRequest{
+				Name: name,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+				return fmt.Errorf("failed to create snapshot: %s", res.Status())
+			}
+
+			id := res.JSON201.Id
+			fmt.Printf("Creating snapshot %s with ID: %s\n", name, id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForSnapshot(cmd.Context(), c, projectID, id, timeout)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Name of the snapshot (default <filesystem>-<timestamp>)")
+	cmd.Flags().Bool("wait", false, "Wait for the snapshot to be ready")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+
+	return cmd
+}
+
+func SnapshotListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [filesystem]",
+		Short: "List snapshots",
+		Long:  "List the snapshots of all filesystems, or of the filesystem given by name or ID.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			params := &atlas.GetFilesystemSnapshotsParams{
+				XPROJECTID: projectID,
+			}
+
+			if len(args) == 1 {
+				fs, err := lookup(cmd.Context(), c, projectID, args[0])
+				if err != nil {
+					return err
+				}
+
+				params.FilesystemId = &fs.Id
+			}
+
+			res, err := c.GetFilesystemSnapshotsWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+				return fmt.Errorf("failed to list snapshots: %s", res.Status())
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(snapshotTable(*res.JSON200))
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func SnapshotDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <snapshot-id>",
+		Short: "Delete a snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.DeleteFilesystemSnapshotsIdWithResponse(cmd.Context(), id, &atlas.DeleteFilesystemSnapshotsIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusNoContent {
+				return fmt.Errorf("failed to delete snapshot: %s", res.Status())
+			}
+
+			fmt.Printf("Deleting snapshot with ID: %s\n", id)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func SnapshotRestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <snapshot-id>",
+		Short: "Restore a filesystem from a snapshot",
+		Long:  "Restore the snapshotted filesystem to the state of the snapshot. All changes made since the snapshot are lost; use 'fluidctl filesystems clone --from-snapshot' to restore into a new filesystem instead.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			snapshot, err := getSnapshot(cmd.Context(), c, projectID, id)
+			if err != nil {
+				return err
+			}
+
+			if !utils.MustGetBoolFlag(cmd, "yes") {
+				fmt.Printf("Restoring snapshot %s overwrites all data on filesystem %s. Continue? [y/N] ", snapshot.Name, snapshot.FilesystemId)
+
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+					return fmt.Errorf("restore aborted")
+				}
+			}
+
+			// The restore time before the restore tells a finished restore
+			// apart from the filesystem not having started it yet.
+			var restoredAt *time.Time
+			if wait {
+				fs, err := getFilesystem(cmd.Context(), c, projectID, snapshot.FilesystemId)
+				if err != nil {
+					return err
+				}
+				restoredAt = fs.RestoredAt
+			}
+
+			res, err := c.PostFilesystemSnapshotsIdRestoreWithResponse(cmd.Context(), id, &atlas.PostFilesystemSnapshotsIdRestoreParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+			case http.StatusConflict:
+				return fmt.Errorf("cannot restore filesystem %s: detach it from all instances first", snapshot.FilesystemId)
+			default:
+				return fmt.Errorf("failed to restore snapshot: %s", res.Status())
+			}
+
+			fmt.Printf("Restoring filesystem with ID: %s from snapshot with ID: %s\n", snapshot.FilesystemId, id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForRestore(cmd.Context(), c, projectID, snapshot.FilesystemId, restoredAt, timeout)
+		},
+	}
+
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().Bool("wait", false, "Wait for the filesystem to be ready")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+
+	return cmd
+}
+
+func CloneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone",
+		Short: "Create a filesystem from a snapshot",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			name := utils.MustGetStringFlag(cmd, "name")
+			sizeFlag := utils.MustGetStringFlag(cmd, "size")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			snapshotID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "from-snapshot"))
+			if err != nil {
+				return fmt.Errorf("invalid snapshot ID: %w", err)
+			}
+
+			var newSize int64
+			var size string
+			if sizeFlag != "" {
+				newSize, size, err = parseSize(sizeFlag)
+				if err != nil {
+					return err
+				}
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			snapshot, err := getSnapshot(cmd.Context(), c, projectID, snapshotID)
+			if err != nil {
+				return err
+			}
+
+			if snapshot.State != snapshotReady {
+				return fmt.Errorf("snapshot %s is %s, it must be ready", snapshotID, snapshot.State)
+			}
+
+			if size == "" {
+				size = snapshot.Size
+			} else if snapshotSize, err := bytesize.Parse(snapshot.Size); err == nil && newSize < snapshotSize {
+				return fmt.Errorf("size %s is smaller than the snapshot (%s)", bytesize.Format(newSize), bytesize.Format(snapshotSize))
+			}
+
+			res, err := c.PostFilesystemsWithResponse(cmd.Context(), &atlas.PostFilesystemsParams{
+				XPROJECTID: projectID,
+			}, atlas.FilesystemsPostRequest{
+				Name:       name,
+				Size:       size,
+				SnapshotId: &snapshotID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+				return fmt.Errorf("failed to create filesystem: %s", res.Status())
+			}
+
+			fmt.Printf("Creating filesystem %s with ID: %s\n", name, res.JSON201.Id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForFilesystem(cmd.Context(), c, projectID, res.JSON201.Id, timeout)
+		},
+	}
+
+	cmd.Flags().String("from-snapshot", "", "ID of the snapshot to create the filesystem from")
+	cmd.Flags().String("name", "", "Name of the filesystem")
+	cmd.Flags().String("size", "", "Size of the filesystem (default the size of the snapshot)")
+	cmd.Flags().Bool("wait", false, "Wait for the filesystem to be ready")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+	cmd.MarkFlagRequired("from-snapshot")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+type snapshotTable []atlas.FilesystemSnapshot
+
+func (t snapshotTable) Header() []string {
+	return []string{"NAME", "ID", "FILESYSTEM", "SIZE", "STATE", "CREATED"}
+}
+
+func (t snapshotTable) Rows() [][]string {
+	rows := [][]string{}
+	for _, snapshot := range t {
+		rows = append(rows, []string{snapshot.Name, snapshot.Id.String(), snapshot.FilesystemId.String(), humanSize(snapshot.Size), string(snapshot.State), snapshot.CreatedAt.Format("2006-01-02 15:04")})
+	}
+
+	return rows
+}
+
+func getSnapshot(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (*atlas.FilesystemSnapshot, error) {
+	res, err := c.GetFilesystemSnapshotsIdWithResponse(ctx, id, &atlas.GetFilesystemSnapshotsIdParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() == http.StatusNotFound {
+		return nil, fmt.Errorf("snapshot not found: %s", id)
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to get snapshot: %s", res.Status())
+	}
+
+	return res.JSON200, nil
+}
+
+func waitForSnapshot(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, timeout time.Duration) error {
+	fmt.Printf("Waiting for snapshot %s to be %s\n", id, snapshotReady)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("snapshot %s to be %s", id, snapshotReady), func(ctx context.Context) (bool, error) {
+		snapshot, err := getSnapshot(ctx, c, projectID, id)
+		if err != nil {
+			return false, err
+		}
+
+		if snapshot.State == snapshotError {
+			return false, fmt.Errorf("snapshot %s failed", id)
+		}
+
+		return snapshot.State == snapshotReady, nil
+	})
+}
+
+func waitForFilesystem(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, timeout time.Duration) error {
+	fmt.Printf("Waiting for filesystem %s to be %s\n", id, filesystemReady)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("filesystem %s to be %s", id, filesystemReady), func(ctx context.Context) (bool, error) {
+		fs, err := getFilesystem(ctx, c, projectID, id)
+		if err != nil {
+			return false, err
+		}
+
+		if fs.State == filesystemError {
+			return false, fmt.Errorf("filesystem %s failed", id)
+		}
+
+		return fs.State == filesystemReady, nil
+	})
+}
+
+// waitForRestore waits for a restore of the filesystem to finish. The
+// filesystem can still be ready from before the restore, and a quick restore
+// can be over between two polls, so it is done once the filesystem is ready
+// with a restore time later than previous, the one it had before.
+func waitForRestore(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, previous *time.Time, timeout time.Duration) error {
+	fmt.Printf("Waiting for filesystem %s to be restored\n", id)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("filesystem %s to be restored", id), func(ctx context.Context) (bool, error) {
+		fs, err := getFilesystem(ctx, c, projectID, id)
+		if err != nil {
+			return false, err
+		}
+
+		if fs.State == filesystemError {
+			return false, fmt.Errorf("filesystem %s failed", id)
+		}
+
+		return fs.State == filesystemReady && restoredAfter(fs, previous), nil
+	})
+}
+
+// restoredAfter reports whether the filesystem has been restored after
+// previous. previous is nil if it had never been restored.
+func restoredAfter(fs *atlas.Filesystem, previous *time.Time) bool {
+	if fs.RestoredAt == nil {
+		return false
+	}
+
+	return previous == nil || fs.RestoredAt.After(*previous)
+}
+
+func getFilesystem(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (*atlas.Filesystem, error) {
+	res, err := c.GetFilesystemsIdWithResponse(ctx, id, &atlas.GetFilesystemsIdParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to get filesystem: %s", res.Status())
+	}
+
+	return res.JSON200, nil
+}
@@ -0,0 +1,34 @@
+package filesystem
+
+import (
+	"testing"
+	"time"
+
+	atlas "github.com/fluidstackio/atlas-client-go/v1alpha1"
+)
+
+func TestRestoredAfter(t *testing.T) {
+	before := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	after := before.Add(3 * time.Second)
+
+	tests := []struct {
+		name       string
+		restoredAt *time.Time
+		previous   *time.Time
+		want       bool
+	}{
+		{name: "not restored yet", restoredAt: &before, previous: &before, want: false},
+		{name: "restored", restoredAt: &after, previous: &before, want: true},
+		{name: "first restore", restoredAt: &after, want: true},
+		{name: "never restored", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := &atlas.Filesystem{State: filesystemReady, RestoredAt: tt.restoredAt}
+			if got := restoredAfter(fs, tt.previous); got != tt.want {
+				t.Errorf("restoredAfter() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
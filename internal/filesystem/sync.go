@@ -0,0 +1,451 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	atlas "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	images "github.com/fluidstackio/fluidctl/internal/image"
+	"github.com/fluidstackio/fluidctl/internal/sshkey"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/fluidstackio/fluidctl/internal/wait"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	instanceRunning atlas.InstanceState = "running"
+	instanceError   atlas.InstanceState = "error"
+)
+
+// helperSSHOptions skips host key checks for helper instances, whose host
+// keys are new every time and never reused.
+var helperSSHOptions = []string{"-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null", "-o", "LogLevel=ERROR"}
+
+type helperUserData struct {
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys"`
+	Packages          []string `yaml:"packages"`
+}
+
+func SyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync <src> <dest>",
+		Short: "Copy data to or from a filesystem",
+		Long: `Copy data between a local path and a filesystem with rsync over SSH. One of
+the paths must be in the format '<filesystem>:<path>', where filesystem is a
+name or ID and path is relative to the root of the filesystem, e.g.
+
+  fluidctl filesystems sync ./datasets datasets:/imagenet
+
+Unless --instance is given, a helper instance with the filesystem attached is
+created for the transfer and deleted afterwards. Interrupted transfers resume
+when the same command is run again.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			instanceFlag := utils.MustGetStringFlag(cmd, "instance")
+			mount := utils.MustGetStringFlag(cmd, "mount")
+			instanceType := utils.MustGetStringFlag(cmd, "type")
+			image := utils.MustGetStringFlag(cmd, "image")
+			sshUser := utils.MustGetStringFlag(cmd, "ssh-user")
+			identity := utils.MustGetStringFlag(cmd, "identity")
+			keepHelper := utils.MustGetBoolFlag(cmd, "keep-helper")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			ref, remotePath, src, dest, err := parseSyncPaths(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if mount != "" && !path.IsAbs(mount) {
+				return fmt.Errorf("invalid mount path %q: must be absolute", mount)
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := atlas.NewClientWithResponses(url+"/api/v1alpha1/", atlas.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			fs, err := lookup(cmd.Context(), c, projectID, ref)
+			if err != nil {
+				return err
+			}
+
+			if mount == "" {
+				mount = "/mnt/" + fs.Name
+			}
+
+			// Cancel on interrupt so the helper instance is still torn down.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			sshOptions := []string{}
+			if identity != "" {
+				sshOptions = append(sshOptions, "-i", identity)
+			}
+
+			var instance *atlas.Instance
+			if instanceFlag != "" {
+				id, err := uuid.Parse(instanceFlag)
+				if err != nil {
+					return fmt.Errorf("invalid instance ID: %w", err)
+				}
+
+				instance, err = getInstance(ctx, c, projectID, id)
+				if err != nil {
+					return err
+				}
+
+				if instance.State != instanceRunning || instance.Ip == nil {
+					return fmt.Errorf("instance %s is %s, it must be running", id, instance.State)
+				}
+			} else {
+				var keys []string
+				if identity != "" {
+					keys, err = sshkey.ReadFile(identity + ".pub")
+				} else {
+					keys, err = sshkey.Default()
+				}
+				if err != nil {
+					return fmt.Errorf("failed to read SSH public key for the helper instance: %w", err)
+				}
+
+				userData, err := yaml.Marshal(helperUserData{
+					SSHAuthorizedKeys: keys,
+					Packages:          []string{"rsync"},
+				})
+				if err != nil {
+					return err
+				}
+				userData = append([]byte("#cloud-config\n"), userData...)
+
+				req := atlas.InstancesPostRequest{
+					Name:     "fluidctl-sync-" + fs.Id.String()[:8],
+					Type:     instanceType,
+					UserData: &userData,
+				}
+
+				if image != "" {
+					imageURL, err := images.Resolve(ctx, c, image)
+					if err != nil {
+						return err
+					}
+
+					req.Image = &imageURL
+				}
+
+				res, err := c.PostInstancesWithResponse(ctx, &atlas.PostInstancesParams{
+					XPROJECTID: projectID,
+				}, req)
+				if err != nil {
+					return err
+				}
+
+				if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+					return fmt.Errorf("failed to create helper instance: %s", res.Status())
+				}
+
+				id := res.JSON201.Id
+				fmt.Printf("Creating helper instance with ID: %s\n", id)
+
+				if keepHelper {
+					defer fmt.Printf("Keeping helper instance with ID: %s\n", id)
+				} else {
+					defer deleteHelper(context.WithoutCancel(ctx), c, projectID, id)
+				}
+
+				if err := attachHelper(ctx, c, projectID, id, Attachment{ID: fs.Id, MountPath: mount}); err != nil {
+					return err
+				}
+
+				instance, err = waitForHelper(ctx, c, projectID, id, timeout)
+				if err != nil {
+					return err
+				}
+
+				sshOptions = append(sshOptions, helperSSHOptions...)
+			}
+
+			host := sshUser + "@" + *instance.Ip
+
+			if instanceFlag == "" {
+				if err := waitForMount(ctx, host, sshOptions, mount, timeout); err != nil {
+					return err
+				}
+			}
+
+			remote := host + ":" + remoteSyncPath(mount, remotePath)
+			if src == "" {
+				src = remote
+			} else {
+				dest = remote
+			}
+
+			rsyncArgs := []string{
+				"--archive",
+				"--compress",
+				"--partial",
+				"--progress",
+				"--human-readable",
+				"--rsync-path", "sudo rsync",
+				"--rsh", rshCommand(sshOptions),
+			}
+			if utils.MustGetBoolFlag(cmd, "delete") {
+				rsyncArgs = append(rsyncArgs, "--delete")
+			}
+			rsyncArgs = append(rsyncArgs, src, dest)
+
+			rsync := exec.CommandContext(ctx, "rsync", rsyncArgs...)
+			rsync.Stdin = os.Stdin
+			rsync.Stdout = os.Stdout
+			rsync.Stderr = os.Stderr
+
+			if err := rsync.Run(); err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("sync interrupted, run the same command again to resume")
+				}
+
+				return fmt.Errorf("rsync failed: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("instance", "", "Use this running instance, which has the filesystem mounted, instead of a helper instance")
+	cmd.Flags().String("mount", "", "Mount path of the filesystem on the instance (default /mnt/<filesystem name>)")
+	cmd.Flags().String("type", "cpu.2x", "Instance type of the helper instance")
+	cmd.Flags().String("image", "", "Image name, alias or URL of the helper instance")
+	cmd.Flags().String("ssh-user", "ubuntu", "User for SSH connections to the instance")
+	cmd.Flags().StringP("identity", "i", "", "SSH private key; its .pub file is authorized on the helper instance")
+	cmd.Flags().Bool("keep-helper", false, "Do not delete the helper instance afterwards")
+	cmd.Flags().Bool("delete", false, "Delete files in the destination that are not in the source")
+	cmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time to wait for the helper instance")
+	cmd.RegisterFlagCompletionFunc("image", images.Complete)
+
+	return cmd
+}
+
+// parseSyncPaths splits the sync arguments into the filesystem reference, the
+// path on the filesystem and the local source or destination. Exactly one of
+// src and dest is returned, the other is filled in once the instance is known.
+func parseSyncPaths(srcArg string, destArg string) (ref string, remotePath string, src string, dest string, err error) {
+	srcRef, srcPath, srcRemote := splitRemotePath(srcArg)
+	destRef, destPath, destRemote := splitRemotePath(destArg)
+
+	switch {
+	case srcRemote && !destRemote:
+		return srcRef, srcPath, "", destArg, nil
+	case destRemote && !srcRemote:
+		return destRef, destPath, srcArg, "", nil
+	default:
+		return "", "", "", "", fmt.Errorf("exactly one of source and destination must be a filesystem path (in the format '<filesystem>:<path>')")
+	}
+}
+
+// splitRemotePath splits 'fs:/path'. Local paths containing a colon can be
+// given as ./path.
+func splitRemotePath(s string) (string, string, bool) {
+	ref, p, found := strings.Cut(s, ":")
+	if !found || ref == "" || strings.Contains(ref, "/") {
+		return "", "", false
+	}
+
+	return ref, p, true
+}
+
+// remoteSyncPath joins the mount path and the path on the filesystem, keeping
+// a trailing slash since it changes what rsync copies.
+func remoteSyncPath(mount string, p string) string {
+	joined := path.Join(mount, p)
+	if strings.HasSuffix(p, "/") && joined != "/" {
+		joined += "/"
+	}
+
+	return joined
+}
+
+// lookup finds a filesystem by name or ID.
+func lookup(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, ref string) (*atlas.Filesystem, error) {
+	res, err := c.GetFilesystemsWithResponse(ctx, &atlas.GetFilesystemsParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list filesystems: %s", res.Status())
+	}
+
+	var found *atlas.Filesystem
+	for _, fs := range *res.JSON200 {
+		if fs.Id.String() == ref {
+			return &fs, nil
+		}
+
+		if fs.Name == ref {
+			if found != nil {
+				return nil, fmt.Errorf("filesystem name %q is ambiguous, use the ID instead", ref)
+			}
+
+			found = &fs
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("filesystem not found: %s", ref)
+	}
+
+	return found, nil
+}
+
+func getInstance(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (*atlas.Instance, error) {
+	res, err := c.GetInstancesIdWithResponse(ctx, id, &atlas.GetInstancesIdParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to get instance: %s", res.Status())
+	}
+
+	return res.JSON200, nil
+}
+
+// waitForHelper waits for the helper instance to be running with an address.
+func waitForHelper(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, timeout time.Duration) (*atlas.Instance, error) {
+	fmt.Printf("Waiting for helper instance %s to be running\n", id)
+
+	var instance *atlas.Instance
+	err := wait.Poll(ctx, timeout, fmt.Sprintf("helper instance %s to be running", id), func(ctx context.Context) (bool, error) {
+		var err error
+		instance, err = getInstance(ctx, c, projectID, id)
+		if err != nil {
+			return false, err
+		}
+
+		if instance.State == instanceError {
+			return false, fmt.Errorf("helper instance %s failed to start", id)
+		}
+
+		return instance.State == instanceRunning && instance.Ip != nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// waitForMount waits until SSH is up and the filesystem is mounted on the
+// helper instance.
+func waitForMount(ctx context.Context, host string, sshOptions []string, mount string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fmt.Printf("Waiting for filesystem to be mounted at %s\n", mount)
+
+	args := append([]string{"-o", "ConnectTimeout=10", "-o", "BatchMode=yes"}, sshOptions...)
+	args = append(args, host, "cloud-init status --wait >/dev/null; mountpoint -q "+shellQuote(mount))
+
+	for {
+		err := exec.CommandContext(ctx, "ssh", args...).Run()
+		if err == nil {
+			return nil
+		}
+
+		// ssh exits with 255 if it cannot connect, keep trying until the
+		// instance accepts connections.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() != 255 {
+			return fmt.Errorf("filesystem is not mounted at %s on the helper instance", mount)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for SSH on the helper instance")
+		case <-time.After(wait.Interval):
+		}
+	}
+}
+
+func attachHelper(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, attachment Attachment) error {
+	res, err := c.PostInstancesIdFilesystemsWithResponse(ctx, id, &atlas.PostInstancesIdFilesystemsParams{
+		XPROJECTID: projectID,
+	}, attachment.Request())
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("failed to attach filesystem to helper instance: %s", res.Status())
+	}
+
+	return nil
+}
+
+// rshCommand returns the ssh command line for rsync's --rsh. rsync splits it
+// on spaces itself, honouring single and double quotes but not backslashes,
+// so every argument is quoted.
+func rshCommand(sshOptions []string) string {
+	args := []string{"ssh"}
+	for _, option := range sshOptions {
+		args = append(args, shellQuote(option))
+	}
+
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell and for rsync's --rsh parsing. Single
+// quotes within s are closed and double quoted, as neither allows escaping
+// them with a backslash inside single quotes.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@,+") == "" {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func deleteHelper(ctx context.Context, c *atlas.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) {
+	fmt.Printf("Deleting helper instance with ID: %s\n", id)
+
+	res, err := c.DeleteInstancesIdWithResponse(ctx, id, &atlas.DeleteInstancesIdParams{
+		XPROJECTID: projectID,
+	})
+	if err == nil && res.StatusCode() != http.StatusNoContent {
+		err = fmt.Errorf("%s", res.Status())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to delete helper instance %s, delete it with 'fluidctl instances delete --id %s': %s\n", id, id, err)
+	}
+}
@@ -0,0 +1,67 @@
+package filesystem
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "StrictHostKeyChecking=no", want: "StrictHostKeyChecking=no"},
+		{in: "/home/me/.ssh/id_ed25519", want: "/home/me/.ssh/id_ed25519"},
+		{in: "", want: "''"},
+		{in: "/home/me/my keys/id", want: "'/home/me/my keys/id'"},
+		{in: "it's", want: `'it'"'"'s'`},
+		{in: "$HOME", want: "'$HOME'"},
+		{in: `a\b`, want: `'a\b'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRshCommand(t *testing.T) {
+	got := rshCommand([]string{"-i", "/home/me/my keys/id", "-o", "LogLevel=ERROR"})
+	want := "ssh -i '/home/me/my keys/id' -o LogLevel=ERROR"
+
+	if got != want {
+		t.Errorf("rshCommand() = %s, want %s", got, want)
+	}
+}
+
+func TestParseSyncPaths(t *testing.T) {
+	tests := []struct {
+		name                               string
+		src, dest                          string
+		ref, remotePath, wantSrc, wantDest string
+		wantErr                            bool
+	}{
+		{name: "upload", src: "./data", dest: "datasets:/imagenet", ref: "datasets", remotePath: "/imagenet", wantSrc: "./data"},
+		{name: "download", src: "datasets:/imagenet", dest: "./data", ref: "datasets", remotePath: "/imagenet", wantDest: "./data"},
+		{name: "local path with colon", src: "./a:b", dest: "datasets:", ref: "datasets", wantSrc: "./a:b"},
+		{name: "both local", src: "./a", dest: "./b", wantErr: true},
+		{name: "both remote", src: "a:/x", dest: "b:/y", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, remotePath, src, dest, err := parseSyncPaths(tt.src, tt.dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSyncPaths(%q, %q) expected error", tt.src, tt.dest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSyncPaths(%q, %q) error: %v", tt.src, tt.dest, err)
+			}
+
+			if ref != tt.ref || remotePath != tt.remotePath || src != tt.wantSrc || dest != tt.wantDest {
+				t.Errorf("parseSyncPaths(%q, %q) = %q, %q, %q, %q", tt.src, tt.dest, ref, remotePath, src, dest)
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package format
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -10,8 +16,9 @@ import (
 type Format string
 
 const (
-	JSON Format = "json"
-	YAML Format = "yaml"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
 )
 
 type Marshal interface {
@@ -30,10 +37,162 @@ func (y *YAMLMarshaller) Marshal(v any) ([]byte, error) {
 	return yaml.Marshal(v)
 }
 
+// Tabular is implemented by values that define their own table layout, for
+// example to show sizes in human units. Other values are laid out from their
+// fields.
+type Tabular interface {
+	Header() []string
+	Rows() [][]string
+}
+
 type TableMarshaller struct{}
 
 func (t *TableMarshaller) Marshal(v any) ([]byte, error) {
-	return nil, fmt.Errorf("table marshalling not implemented")
+	if tabular, ok := v.(Tabular); ok {
+		return renderTable(tabular.Header(), tabular.Rows())
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return renderTable(nil, nil)
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem := rv.Type().Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+
+		if elem.Kind() != reflect.Struct {
+			rows := [][]string{}
+			for i := 0; i < rv.Len(); i++ {
+				rows = append(rows, []string{cell(rv.Index(i))})
+			}
+
+			return renderTable([]string{"VALUE"}, rows)
+		}
+
+		fields := tableFields(elem)
+
+		header := []string{}
+		for _, f := range fields {
+			header = append(header, f.name)
+		}
+
+		rows := [][]string{}
+		for i := 0; i < rv.Len(); i++ {
+			item := reflect.Indirect(rv.Index(i))
+
+			row := []string{}
+			for _, f := range fields {
+				if item.IsValid() {
+					row = append(row, cell(item.FieldByIndex(f.index)))
+				} else {
+					row = append(row, "")
+				}
+			}
+
+			rows = append(rows, row)
+		}
+
+		return renderTable(header, rows)
+	case reflect.Struct:
+		rows := [][]string{}
+		for _, f := range tableFields(rv.Type()) {
+			rows = append(rows, []string{f.name, cell(rv.FieldByIndex(f.index))})
+		}
+
+		return renderTable([]string{"FIELD", "VALUE"}, rows)
+	case reflect.Map:
+		rows := [][]string{}
+		for _, key := range rv.MapKeys() {
+			rows = append(rows, []string{cell(key), cell(rv.MapIndex(key))})
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+
+		return renderTable([]string{"KEY", "VALUE"}, rows)
+	default:
+		return []byte(cell(rv)), nil
+	}
+}
+
+type tableField struct {
+	name  string
+	index []int
+}
+
+func tableFields(t reflect.Type) []tableField {
+	fields := []tableField{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+
+		name := f.Name
+		if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		fields = append(fields, tableField{name: strings.ToUpper(name), index: f.Index})
+	}
+
+	return fields
+}
+
+func cell(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return ""
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func renderTable(header []string, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+
+	if len(header) != 0 {
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+	}
+
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
 }
 
 func NewMarshaller(format Format) (Marshal, error) {
@@ -42,6 +201,8 @@ func NewMarshaller(format Format) (Marshal, error) {
 		return &JSONMarshaller{}, nil
 	case YAML:
 		return &YAMLMarshaller{}, nil
+	case Table:
+		return &TableMarshaller{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
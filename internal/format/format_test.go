@@ -0,0 +1,155 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type disk struct {
+	Size string `json:"size"`
+}
+
+type server struct {
+	ID       uuid.UUID         `json:"id"`
+	Name     string            `json:"name"`
+	IP       *string           `json:"ip,omitempty"`
+	Disk     disk              `json:"disk"`
+	Labels   map[string]string `json:"labels"`
+	Created  time.Time         `json:"created_at"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+type summary struct{ total int }
+
+func (s summary) Header() []string { return []string{"TOTAL"} }
+func (s summary) Rows() [][]string { return [][]string{{"3 servers"}} }
+
+func TestTableMarshaller(t *testing.T) {
+	ip := "10.0.0.1"
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := server{
+		ID:       uuid.MustParse("53fb1530-0f68-4668-968b-044bf3bae2af"),
+		Name:     "a",
+		IP:       &ip,
+		Disk:     disk{Size: "100Gi"},
+		Labels:   map[string]string{"team": "ml"},
+		Created:  created,
+		Secret:   "hidden",
+		internal: "hidden",
+	}
+	b := server{Name: "b", Created: created}
+	servers := []server{a, b}
+	serversPtr := &servers
+	var nilServers *[]server
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			name: "tabular",
+			v:    summary{total: 3},
+			want: "TOTAL\n3 servers",
+		},
+		{
+			name: "slice of structs",
+			v:    servers,
+			want: "" +
+				"ID                                     NAME   IP         DISK               LABELS          CREATED_AT\n" +
+				"53fb1530-0f68-4668-968b-044bf3bae2af   a      10.0.0.1   {\"size\":\"100Gi\"}   {\"team\":\"ml\"}   2025-03-01T12:00:00Z\n" +
+				"00000000-0000-0000-0000-000000000000   b                 {\"size\":\"\"}        null            2025-03-01T12:00:00Z",
+		},
+		{
+			name: "slice of pointers with nil",
+			v:    []*server{&b, nil},
+			want: "" +
+				"ID                                     NAME   IP   DISK          LABELS   CREATED_AT\n" +
+				"00000000-0000-0000-0000-000000000000   b           {\"size\":\"\"}   null     2025-03-01T12:00:00Z\n" +
+				"                                                                          ",
+		},
+		{
+			name: "nested pointers",
+			v:    &serversPtr,
+			want: "" +
+				"ID                                     NAME   IP         DISK               LABELS          CREATED_AT\n" +
+				"53fb1530-0f68-4668-968b-044bf3bae2af   a      10.0.0.1   {\"size\":\"100Gi\"}   {\"team\":\"ml\"}   2025-03-01T12:00:00Z\n" +
+				"00000000-0000-0000-0000-000000000000   b                 {\"size\":\"\"}        null            2025-03-01T12:00:00Z",
+		},
+		{
+			name: "nil pointer",
+			v:    nilServers,
+			want: "",
+		},
+		{
+			name: "nil",
+			v:    nil,
+			want: "",
+		},
+		{
+			name: "empty slice",
+			v:    []server{},
+			want: "ID   NAME   IP   DISK   LABELS   CREATED_AT",
+		},
+		{
+			name: "slice of strings",
+			v:    []string{"small", "large"},
+			want: "VALUE\nsmall\nlarge",
+		},
+		{
+			name: "slice of string pointers",
+			v:    []*string{nil, &ip},
+			want: "VALUE\n\n10.0.0.1",
+		},
+		{
+			name: "struct",
+			v:    &b,
+			want: "" +
+				"FIELD        VALUE\n" +
+				"ID           00000000-0000-0000-0000-000000000000\n" +
+				"NAME         b\n" +
+				"IP           \n" +
+				"DISK         {\"size\":\"\"}\n" +
+				"LABELS       null\n" +
+				"CREATED_AT   2025-03-01T12:00:00Z",
+		},
+		{
+			name: "map is sorted by key",
+			v:    map[string]int{"b": 2, "a": 1},
+			want: "KEY   VALUE\na     1\nb     2",
+		},
+		{
+			name: "scalar",
+			v:    42,
+			want: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&TableMarshaller{}).Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMarshaller(t *testing.T) {
+	for _, f := range []Format{JSON, YAML, Table} {
+		if _, err := NewMarshaller(f); err != nil {
+			t.Errorf("NewMarshaller(%q): %v", f, err)
+		}
+	}
+
+	if _, err := NewMarshaller("xml"); err == nil {
+		t.Error("expected error for an unsupported format")
+	}
+}
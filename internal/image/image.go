@@ -0,0 +1,222 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "images",
+		Short: "Browse the image catalog",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		ListCommand(),
+		DescribeCommand(),
+	)
+
+	return &cmd
+}
+
+func ListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list images",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			images, err := List(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(images)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func DescribeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:               "describe <name>",
+		Short:             "describe image",
+		Long:              "Describe an image by name, alias or URL.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: Complete,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			images, err := List(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+
+			image := find(images, args[0])
+			if image == nil {
+				return fmt.Errorf("image not found: %s", args[0])
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(image)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func List(ctx context.Context, c *client.ClientWithResponses) ([]client.Image, error) {
+	res, err := c.GetImagesWithResponse(ctx, &client.GetImagesParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list images: %s", res.Status())
+	}
+
+	return *res.JSON200, nil
+}
+
+func find(images []client.Image, ref string) *client.Image {
+	for _, image := range images {
+		if image.Name == ref || image.Url == ref {
+			return &image
+		}
+
+		if image.Aliases != nil && slices.Contains(*image.Aliases, ref) {
+			return &image
+		}
+	}
+
+	return nil
+}
+
+// Resolve maps an image name or alias such as ubuntu-22.04-cuda to its URL.
+// URLs are passed through unchanged without consulting the catalog, so custom
+// images keep working when the catalog is unavailable.
+func Resolve(ctx context.Context, c *client.ClientWithResponses, ref string) (string, error) {
+	if strings.Contains(ref, "://") {
+		return ref, nil
+	}
+
+	images, err := List(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	if image := find(images, ref); image != nil {
+		return image.Url, nil
+	}
+
+	return "", fmt.Errorf("unknown image %q, see 'fluidctl images list'", ref)
+}
+
+// Complete completes image names and aliases. It only uses cached credentials
+// so that completion never starts an interactive login.
+func Complete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	url := utils.MustGetStringFlag(cmd, "url")
+
+	token, err := auth.CachedLogin(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	images, err := List(cmd.Context(), c)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := []string{}
+	for _, image := range images {
+		description := ""
+		if image.Description != nil {
+			description = "\t" + *image.Description
+		}
+
+		completions = append(completions, image.Name+description)
+		if image.Aliases != nil {
+			for _, alias := range *image.Aliases {
+				completions = append(completions, alias+"\t"+image.Name)
+			}
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,56 @@
+package image
+
+import (
+	"context"
+	"testing"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+)
+
+func TestFind(t *testing.T) {
+	images := []client.Image{
+		{Name: "ubuntu-22.04", Url: "https://images.example.com/ubuntu-22.04.qcow2", Aliases: &[]string{"ubuntu", "jammy"}},
+		{Name: "ubuntu-22.04-cuda", Url: "https://images.example.com/ubuntu-22.04-cuda.qcow2"},
+		{Name: "debian-12", Url: "https://images.example.com/debian-12.qcow2", Aliases: &[]string{}},
+	}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "ubuntu-22.04", want: "ubuntu-22.04"},
+		{ref: "ubuntu-22.04-cuda", want: "ubuntu-22.04-cuda"},
+		{ref: "jammy", want: "ubuntu-22.04"},
+		{ref: "https://images.example.com/debian-12.qcow2", want: "debian-12"},
+		{ref: "Ubuntu-22.04", want: ""},
+		{ref: "ubuntu-22", want: ""},
+		{ref: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		image := find(images, tt.ref)
+
+		got := ""
+		if image != nil {
+			got = image.Name
+		}
+
+		if got != tt.want {
+			t.Errorf("find(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestResolveURLSkipsCatalog(t *testing.T) {
+	// A nil client fails the test if the catalog is listed.
+	ref := "https://example.com/custom.qcow2"
+
+	got, err := Resolve(context.Background(), nil, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != ref {
+		t.Errorf("Resolve(%q) = %q, want it unchanged", ref, got)
+	}
+}
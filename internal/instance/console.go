@@ -0,0 +1,248 @@
+package instance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// consoleEscape is Ctrl-], the same detach key as telnet and virsh console.
+const consoleEscape = 0x1d
+
+var errDetached = errors.New("detached")
+
+func LogsCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "logs <id>",
+		Short: "show instance console and boot log",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			follow := utils.MustGetBoolFlag(cmd, "follow")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			return streamLogs(cmd.Context(), c, projectID, id, follow, os.Stdout)
+		},
+	}
+
+	cmd.Flags().BoolP("follow", "f", false, "Keep streaming new log output")
+
+	return &cmd
+}
+
+func ConsoleCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "console <id>",
+		Short: "attach to the instance serial console",
+		Long:  "Attach to the instance serial console. Press Ctrl-] to detach.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			fd := int(os.Stdin.Fd())
+			if !term.IsTerminal(fd) {
+				return fmt.Errorf("console requires an interactive terminal")
+			}
+
+			req, err := consoleRequest(url, projectID, id)
+			if err != nil {
+				return err
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+			if err := bearerAuth.Intercept(cmd.Context(), req); err != nil {
+				return err
+			}
+
+			conn, res, err := websocket.DefaultDialer.DialContext(cmd.Context(), req.URL.String(), req.Header)
+			if err != nil {
+				if res != nil {
+					return fmt.Errorf("failed to connect to console: %s", res.Status)
+				}
+
+				return fmt.Errorf("failed to connect to console: %w", err)
+			}
+			defer conn.Close()
+
+			state, err := term.MakeRaw(fd)
+			if err != nil {
+				return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+			}
+			defer term.Restore(fd, state)
+
+			fmt.Printf("Connected to console of instance %s. Press Ctrl-] to detach.\r\n", id)
+
+			errc := make(chan error, 2)
+
+			go func() {
+				for {
+					_, data, err := conn.ReadMessage()
+					if err != nil {
+						errc <- err
+						return
+					}
+
+					if _, err := os.Stdout.Write(data); err != nil {
+						errc <- err
+						return
+					}
+				}
+			}()
+
+			go func() {
+				errc <- forwardInput(os.Stdin, func(data []byte) error {
+					return conn.WriteMessage(websocket.BinaryMessage, data)
+				})
+			}()
+
+			err = <-errc
+
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+			fmt.Print("\r\n")
+
+			if errors.Is(err, errDetached) || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+
+			return fmt.Errorf("console connection closed: %w", err)
+		},
+	}
+
+	return &cmd
+}
+
+// streamLogs copies the instance log to w. The raw response is used so that
+// follow can stream the body as it arrives rather than buffering it.
+func streamLogs(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, follow bool, w io.Writer) error {
+	res, err := c.GetInstancesIdLogs(ctx, id, &client.GetInstancesIdLogsParams{
+		XPROJECTID: projectID,
+		Follow:     &follow,
+	})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get instance logs: %s", res.Status)
+	}
+
+	_, err = io.Copy(w, res.Body)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read instance logs: %w", err)
+	}
+
+	return nil
+}
+
+// consoleRequest builds the websocket request for the serial console. The
+// client builds it like any other API request, so the path and the project
+// header are the same, and only the scheme is changed to ws or wss.
+func consoleRequest(apiURL string, projectID uuid.UUID, id uuid.UUID) (*http.Request, error) {
+	u, err := neturl.Parse(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL %q: %w", apiURL, err)
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("invalid API URL %q: must start with http:// or https://", apiURL)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid API URL %q: missing host", apiURL)
+	}
+
+	return client.NewGetInstancesIdConsoleRequest(u.JoinPath("api/v1alpha1").String()+"/", id, &client.GetInstancesIdConsoleParams{
+		XPROJECTID: projectID,
+	})
+}
+
+// forwardInput sends what is read from r until it fails or the detach key is
+// read, in which case it returns errDetached after sending the input before
+// the key.
+func forwardInput(r io.Reader, send func([]byte) error) error {
+	buf := make([]byte, 1024)
+	for {
+		n, err := r.Read(buf)
+
+		data := buf[:n]
+		i := bytes.IndexByte(data, consoleEscape)
+		if i >= 0 {
+			data = data[:i]
+		}
+
+		if len(data) > 0 {
+			if err := send(data); err != nil {
+				return err
+			}
+		}
+
+		if i >= 0 {
+			return errDetached
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package instance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/google/uuid"
+)
+
+func TestConsoleRequest(t *testing.T) {
+	projectID := uuid.MustParse("7b0a4f0e-8f44-4a39-9d53-2c1c6a1c3c11")
+	id := uuid.MustParse("53fb1530-0f68-4668-968b-044bf3bae2af")
+
+	tests := []struct {
+		name    string
+		apiURL  string
+		want    string
+		wantErr bool
+	}{
+		{name: "https", apiURL: "https://api.example.com", want: "wss://api.example.com/api/v1alpha1/instances/" + id.String() + "/console"},
+		{name: "http", apiURL: "http://localhost:8080", want: "ws://localhost:8080/api/v1alpha1/instances/" + id.String() + "/console"},
+		{name: "trailing slash", apiURL: "https://api.example.com/", want: "wss://api.example.com/api/v1alpha1/instances/" + id.String() + "/console"},
+		{name: "path prefix", apiURL: "https://example.com/atlas", want: "wss://example.com/atlas/api/v1alpha1/instances/" + id.String() + "/console"},
+		{name: "no scheme", apiURL: "api.example.com", wantErr: true},
+		{name: "host and port without scheme", apiURL: "localhost:8080", wantErr: true},
+		{name: "other scheme", apiURL: "ftp://api.example.com", wantErr: true},
+		{name: "no host", apiURL: "https://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := consoleRequest(tt.apiURL, projectID, id)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", req.URL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := req.URL.String(); got != tt.want {
+				t.Errorf("URL = %s, want %s", got, tt.want)
+			}
+
+			if got := req.Header.Get("X-PROJECT-ID"); got != projectID.String() {
+				t.Errorf("project header = %q, want %q", got, projectID)
+			}
+		})
+	}
+}
+
+func TestForwardInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr error
+	}{
+		{name: "detach", in: "ls\r\x1dexit\r", want: "ls\r", wantErr: errDetached},
+		{name: "detach only", in: "\x1d", want: "", wantErr: errDetached},
+		{name: "end of input", in: "ls\r", want: "ls\r", wantErr: io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent bytes.Buffer
+			err := forwardInput(strings.NewReader(tt.in), func(data []byte) error {
+				sent.Write(data)
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := sent.String(); got != tt.want {
+				t.Errorf("sent %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardInputSendError(t *testing.T) {
+	closed := errors.New("closed")
+
+	err := forwardInput(strings.NewReader("ls\r"), func(data []byte) error {
+		return closed
+	})
+	if !errors.Is(err, closed) {
+		t.Errorf("error = %v, want %v", err, closed)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	projectID := uuid.MustParse("7b0a4f0e-8f44-4a39-9d53-2c1c6a1c3c11")
+	id := uuid.MustParse("53fb1530-0f68-4668-968b-044bf3bae2af")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1alpha1/instances/"+id.String()+"/logs" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Header.Get("X-PROJECT-ID") != projectID.String() {
+			http.Error(w, "wrong project", http.StatusForbidden)
+			return
+		}
+
+		fmt.Fprintf(w, "follow=%s\nbooting\n", r.URL.Query().Get("follow"))
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClientWithResponses(srv.URL + "/api/v1alpha1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := streamLogs(context.Background(), c, projectID, id, true, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "follow=true\nbooting\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	err = streamLogs(context.Background(), c, uuid.New(), id, false, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("error = %v, want a 403 error", err)
+	}
+}
@@ -0,0 +1,180 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/google/uuid"
+)
+
+// maxConcurrentCreates bounds the number of create requests in flight.
+const maxConcurrentCreates = 8
+
+// rollbackTimeout bounds the deletes of an --atomic rollback. The rollback
+// runs even if the command was interrupted, as that is often why it failed.
+const rollbackTimeout = 2 * time.Minute
+
+type nameData struct {
+	Index int
+}
+
+// instanceNames renders the name template once per instance. Index starts at
+// zero. A single instance is named as given, without templating.
+func instanceNames(pattern string, count int) ([]string, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("count must be at least 1")
+	}
+
+	if count == 1 {
+		return []string{pattern}, nil
+	}
+
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name template: %w", err)
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for i := 0; i < count; i++ {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, nameData{Index: i}); err != nil {
+			return nil, fmt.Errorf("invalid name template: %w", err)
+		}
+
+		name := b.String()
+		if seen[name] {
+			return nil, fmt.Errorf("name %q is not unique, use {{.Index}} in the name when count is greater than 1", name)
+		}
+
+		names = append(names, name)
+		seen[name] = true
+	}
+
+	return names, nil
+}
+
+type createResult struct {
+	name string
+	id   uuid.UUID
+	err  error
+}
+
+// createInstances creates one instance per name concurrently. Failures are
+// reported per instance; with atomic set, the instances that were created are
+// deleted again if any creation (or wait) fails.
+func createInstances(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, req client.InstancesPostRequest, names []string, atomic bool, wait bool, timeout time.Duration) error {
+	results := make([]createResult, len(names))
+	sem := make(chan struct{}, maxConcurrentCreates)
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r := req
+			r.Name = name
+
+			id, err := createInstance(ctx, c, projectID, r)
+			results[i] = createResult{name: name, id: id, err: err}
+		}()
+	}
+	wg.Wait()
+
+	errs := []error{}
+	failed := 0
+	created := []createResult{}
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("failed to create instance %s: %w", r.name, r.err))
+			failed++
+			continue
+		}
+
+		fmt.Printf("Creating instance %s with ID: %s\n", r.name, r.id)
+		created = append(created, r)
+	}
+
+	if wait && (failed == 0 || !atomic) {
+		waitErrs := make([]error, len(created))
+		for i, r := range created {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				waitErrs[i] = waitForState(ctx, c, projectID, r.id, stateRunning, timeout)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range waitErrs {
+			if err != nil {
+				errs = append(errs, err)
+				failed++
+			}
+		}
+	}
+
+	if failed == 0 {
+		return nil
+	}
+
+	if atomic {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+		defer cancel()
+
+		for _, r := range created {
+			fmt.Printf("Rolling back instance %s with ID: %s\n", r.name, r.id)
+
+			if err := deleteInstance(ctx, c, projectID, r.id); err != nil {
+				errs = append(errs, fmt.Errorf("failed to roll back instance %s: %w", r.name, err))
+			}
+		}
+	}
+
+	if len(names) > 1 {
+		return fmt.Errorf("%d of %d instances failed:\n%w", failed, len(names), errors.Join(errs...))
+	}
+
+	return errors.Join(errs...)
+}
+
+func createInstance(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, req client.InstancesPostRequest) (uuid.UUID, error) {
+	res, err := c.PostInstancesWithResponse(ctx, &client.PostInstancesParams{
+		XPROJECTID: projectID,
+	}, req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+		return uuid.Nil, fmt.Errorf("%s", res.Status())
+	}
+
+	return res.JSON201.Id, nil
+}
+
+func deleteInstance(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) error {
+	res, err := c.DeleteInstancesIdWithResponse(ctx, id, &client.DeleteInstancesIdParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() != http.StatusNoContent {
+		return fmt.Errorf("%s", res.Status())
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package instance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInstanceNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		count   int
+		want    []string
+		wantErr bool
+	}{
+		{name: "single", pattern: "trainer", count: 1, want: []string{"trainer"}},
+		{name: "single is not a template", pattern: "{{weird}}", count: 1, want: []string{"{{weird}}"}},
+		{name: "indexed", pattern: "worker-{{.Index}}", count: 3, want: []string{"worker-0", "worker-1", "worker-2"}},
+		{name: "not unique", pattern: "worker", count: 2, wantErr: true},
+		{name: "invalid template", pattern: "worker-{{.Index", count: 2, wantErr: true},
+		{name: "unknown field", pattern: "worker-{{.Name}}", count: 2, wantErr: true},
+		{name: "zero count", pattern: "worker", count: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := instanceNames(tt.pattern, tt.count)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
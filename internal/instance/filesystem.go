@@ -0,0 +1,174 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/filesystem"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+// filesystemMounts returns the cloud-config mounts entries for the attachments
+// that have a mount path.
+func filesystemMounts(attachments []filesystem.Attachment) ([][]string, error) {
+	mounts := [][]string{}
+
+	for _, attachment := range attachments {
+		if attachment.MountPath == "" {
+			if attachment.ReadOnly {
+				return nil, fmt.Errorf("filesystem %s: readonly requires a mount path", attachment.ID)
+			}
+
+			continue
+		}
+
+		mounts = append(mounts, attachment.MountEntry())
+	}
+
+	return mounts, nil
+}
+
+func AttachFilesystemCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "attach-filesystem <instance-id> id=<UUID>|name=<name>[,mount=<path>][,readonly=true]",
+		Short: "attach filesystem to instance",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			attachment, err := filesystem.ParseAttachment(args[1])
+			if err != nil {
+				return err
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			attachments := []filesystem.Attachment{attachment}
+			if err := filesystem.ResolveAttachments(cmd.Context(), c, projectID, attachments); err != nil {
+				return err
+			}
+			if err := filesystem.ValidateAttachments(attachments); err != nil {
+				return err
+			}
+			attachment = attachments[0]
+
+			if err := attachFilesystem(cmd.Context(), c, projectID, id, attachment); err != nil {
+				return err
+			}
+
+			fmt.Printf("Attaching filesystem with ID: %s to instance with ID: %s\n", attachment.ID, id)
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+// attachFilesystem attaches the filesystem to the instance with the mount path
+// and read-only access of the attachment.
+func attachFilesystem(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, attachment filesystem.Attachment) error {
+	res, err := c.PostInstancesIdFilesystemsWithResponse(ctx, id, &client.PostInstancesIdFilesystemsParams{
+		XPROJECTID: projectID,
+	}, attachment.Request())
+	if err != nil {
+		return err
+	}
+
+	switch res.StatusCode() {
+	case http.StatusNoContent:
+	case http.StatusConflict:
+		return fmt.Errorf("filesystem %s is already attached to instance %s", attachment.ID, id)
+	default:
+		return fmt.Errorf("failed to attach filesystem: %s", res.Status())
+	}
+
+	return nil
+}
+
+func DetachFilesystemCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "detach-filesystem <instance-id> <filesystem-id>",
+		Short: "detach filesystem from instance",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			filesystemID, err := uuid.Parse(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid filesystem id: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.DeleteInstancesIdFilesystemsFilesystemIdWithResponse(cmd.Context(), id, filesystemID, &client.DeleteInstancesIdFilesystemsFilesystemIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusNoContent:
+			case http.StatusNotFound:
+				return fmt.Errorf("filesystem %s is not attached to instance %s", filesystemID, id)
+			default:
+				return fmt.Errorf("failed to detach filesystem: %s", res.Status())
+			}
+
+			fmt.Printf("Detaching filesystem with ID: %s from instance with ID: %s\n", filesystemID, id)
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
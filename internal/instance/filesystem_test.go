@@ -0,0 +1,59 @@
+package instance
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fluidstackio/fluidctl/internal/filesystem"
+	"github.com/google/uuid"
+)
+
+func TestFilesystemMounts(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name        string
+		attachments []filesystem.Attachment
+		want        [][]string
+		wantErr     bool
+	}{
+		{
+			name: "none",
+			want: [][]string{},
+		},
+		{
+			name:        "unmounted filesystems are skipped",
+			attachments: []filesystem.Attachment{{ID: a}, {ID: b, MountPath: "/mnt/b"}},
+			want:        [][]string{{b.String(), "/mnt/b", "virtiofs", "defaults,nofail", "0", "0"}},
+		},
+		{
+			name:        "read-only",
+			attachments: []filesystem.Attachment{{ID: a, MountPath: "/mnt/a", ReadOnly: true}},
+			want:        [][]string{{a.String(), "/mnt/a", "virtiofs", "defaults,nofail,ro", "0", "0"}},
+		},
+		{
+			name:        "read-only without mount path",
+			attachments: []filesystem.Attachment{{ID: a, ReadOnly: true}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filesystemMounts(tt.attachments)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filesystemMounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
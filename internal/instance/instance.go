@@ -3,17 +3,21 @@ package instance
 import (
 	"fmt"
 	"net/http"
-	"os"
-	"strings"
+	"slices"
+	"time"
 
 	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
 	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/filesystem"
 	"github.com/fluidstackio/fluidctl/internal/format"
+	images "github.com/fluidstackio/fluidctl/internal/image"
+	"github.com/fluidstackio/fluidctl/internal/instancetype"
+	"github.com/fluidstackio/fluidctl/internal/sshkey"
 	"github.com/fluidstackio/fluidctl/internal/utils"
 	"github.com/google/uuid"
 	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
+	"github.com/spf13/pflag"
 )
 
 func Command() *cobra.Command {
@@ -32,145 +36,213 @@ func Command() *cobra.Command {
 		DeleteCommand(),
 		ListCommand(),
 		DescribeCommand(),
+		StartCommand(),
+		StopCommand(),
+		RebootCommand(),
+		LogsCommand(),
+		ConsoleCommand(),
+		AttachFilesystemCommand(),
+		DetachFilesystemCommand(),
+		TemplateCommand(),
 	)
 
 	return &cmd
 }
 
-type UserData struct {
-	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
-}
-
 func CreateCommand() *cobra.Command {
 	cmd := cobra.Command{
 		Use:   "create",
 		Short: "create instance",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if template := utils.MustGetStringFlag(cmd, "template"); template != "" {
+				if err := applyTemplate(cmd, template); err != nil {
+					return err
+				}
+			}
+
 			url := utils.MustGetStringFlag(cmd, "url")
 			name := utils.MustGetStringFlag(cmd, "name")
 			image := utils.MustGetStringFlag(cmd, "image")
 			instanceType := utils.MustGetStringFlag(cmd, "type")
 			preemptible := utils.MustGetBoolFlag(cmd, "preemptible")
 			ephemeral := utils.MustGetBoolFlag(cmd, "ephemeral")
+			count := utils.MustGetIntFlag(cmd, "count")
+			atomic := utils.MustGetBoolFlag(cmd, "atomic")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
 
-			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			names, err := instanceNames(name, count)
 			if err != nil {
-				return fmt.Errorf("invalid project ID: %w", err)
+				return err
 			}
 
-			instance := client.InstancesPostRequest{
-				Name:        name,
-				Preemptible: &preemptible,
-				Ephemeral:   &ephemeral,
-				Type:        instanceType,
+			sshAuthorizedKeys, err := sshkey.FromFlags(cmd)
+			if err != nil {
+				return err
 			}
 
-			if image != "" {
-				instance.Image = &image
+			if !utils.MustGetBoolFlag(cmd, "no-project-keys") {
+				// An invalid project ID is reported when the instance is
+				// created.
+				if projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project")); err == nil {
+					sshAuthorizedKeys, err = sshkey.WithProjectKeys(sshAuthorizedKeys, projectID)
+					if err != nil {
+						return err
+					}
+				}
 			}
 
-			userDataPath := utils.MustGetStringFlag(cmd, "user-data")
-			sshAuthrorizedKeyPaths := utils.MustGetStringArrayFlag(cmd, "ssh-authorized-key")
-
-			if userDataPath != "" {
-				userData, err := os.ReadFile(userDataPath)
+			writeFiles := []WriteFile{}
+			for _, s := range utils.MustGetStringArrayFlag(cmd, "write-file") {
+				writeFile, err := parseWriteFileFlag(s)
 				if err != nil {
-					return fmt.Errorf("failed to read user-data file: %w", err)
+					return err
 				}
 
-				if len(sshAuthrorizedKeyPaths) != 0 {
-					return fmt.Errorf("cannot specify both user-data and ssh-authorized-key")
+				writeFiles = append(writeFiles, writeFile)
+			}
+
+			env := ""
+			for _, s := range utils.MustGetStringArrayFlag(cmd, "env") {
+				line, err := parseEnvFlag(s)
+				if err != nil {
+					return err
 				}
 
-				instance.UserData = &userData
-			} else {
-				sshAuthorizedKeys := []string{}
-				for _, sshAuthorizedKeyPath := range sshAuthrorizedKeyPaths {
-					sshAuthorizedKey, err := os.ReadFile(sshAuthorizedKeyPath)
-					if err != nil {
-						return fmt.Errorf("failed to read ssh public-key file: %w", err)
-					}
+				env += line + "\n"
+			}
+			if env != "" {
+				writeFiles = append(writeFiles, WriteFile{Path: "/etc/environment", Content: env, Append: true})
+			}
 
-					sshAuthorizedKeys = append(sshAuthorizedKeys, strings.TrimSpace(string(sshAuthorizedKey)))
+			attachments := []filesystem.Attachment{}
+			for _, fs := range utils.MustGetStringArrayFlag(cmd, "filesystem") {
+				attachment, err := filesystem.ParseAttachment(fs)
+				if err != nil {
+					return err
 				}
 
-				b, err := yaml.Marshal(&UserData{
-					SSHAuthorizedKeys: sshAuthorizedKeys,
-				})
+				attachments = append(attachments, attachment)
+			}
+
+			printUserData := utils.MustGetBoolFlag(cmd, "print-user-data")
+
+			// Printing the user-data only needs the API to resolve filesystem names.
+			var c *client.ClientWithResponses
+			var projectID uuid.UUID
+			if !printUserData || slices.ContainsFunc(attachments, func(a filesystem.Attachment) bool { return a.Name != "" }) {
+				projectID, err = uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
 				if err != nil {
-					return fmt.Errorf("failed to marshal user-data: %w", err)
+					return fmt.Errorf("invalid project ID: %w", err)
 				}
 
-				userData := append([]byte("#cloud-config\n"), b...)
-				instance.UserData = &userData
-			}
+				token, err := auth.Login(cmd)
+				if err != nil {
+					return fmt.Errorf("failed to login: %w", err)
+				}
+				bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+				if err != nil {
+					return fmt.Errorf("failed to create bearer auth: %w", err)
+				}
 
-			filesystems := []uuid.UUID{}
-			for _, fs := range utils.MustGetStringArrayFlag(cmd, "filesystem") {
-				id, err := parseFilesystemFlag(fs)
+				c, err = client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
 				if err != nil {
 					return err
 				}
 
-				filesystems = append(filesystems, id)
-			}
-			if len(filesystems) != 0 {
-				instance.Filesystems = &filesystems
+				if err := filesystem.ResolveAttachments(cmd.Context(), c, projectID, attachments); err != nil {
+					return err
+				}
 			}
 
-			token, err := auth.Login(cmd)
-			if err != nil {
-				return fmt.Errorf("failed to login: %w", err)
+			if err := filesystem.ValidateAttachments(attachments); err != nil {
+				return err
 			}
-			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+
+			mounts, err := filesystemMounts(attachments)
 			if err != nil {
-				return fmt.Errorf("failed to create bearer auth: %w", err)
+				return err
 			}
 
-			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			userData, err := buildUserData(utils.MustGetStringArrayFlag(cmd, "user-data"), UserData{
+				SSHAuthorizedKeys: sshAuthorizedKeys,
+				Packages:          utils.MustGetStringArrayFlag(cmd, "package"),
+				RunCmd:            utils.MustGetStringArrayFlag(cmd, "run-cmd"),
+				WriteFiles:        writeFiles,
+				Mounts:            mounts,
+			})
 			if err != nil {
 				return err
 			}
 
-			res, err := c.PostInstancesWithResponse(cmd.Context(), &client.PostInstancesParams{
-				XPROJECTID: projectID,
-			}, instance)
-			if err != nil {
+			if printUserData {
+				fmt.Print(string(userData))
+				return nil
+			}
+
+			instance := client.InstancesPostRequest{
+				Name:        name,
+				Preemptible: &preemptible,
+				Ephemeral:   &ephemeral,
+				Type:        instanceType,
+				UserData:    &userData,
+			}
+
+			if len(attachments) != 0 {
+				filesystems := []uuid.UUID{}
+				for _, attachment := range attachments {
+					filesystems = append(filesystems, attachment.ID)
+				}
+
+				instance.Filesystems = &filesystems
+			}
+
+			if err := instancetype.Validate(cmd.Context(), c, instanceType); err != nil {
 				return err
 			}
 
-			if res.StatusCode() != http.StatusCreated {
-				return fmt.Errorf("failed to create instance: %s", res.Status())
+			if image != "" {
+				imageURL, err := images.Resolve(cmd.Context(), c, image)
+				if err != nil {
+					return err
+				}
+
+				instance.Image = &imageURL
 			}
 
-			return nil
+			return createInstances(cmd.Context(), c, projectID, instance, names, atomic, wait, timeout)
 		},
 	}
 
-	cmd.Flags().String("name", "", "Name of the instance")
-	cmd.Flags().String("user-data", "", "Path to cloud-init user-data")
-	cmd.Flags().StringArray("ssh-authorized-key", []string{}, "Path to SSH public key")
-	cmd.Flags().String("image", "", "Image URL")
-	cmd.Flags().StringArray("filesystem", []string{}, "Filesystems to attach (in the format 'id=<UUID>')")
-	cmd.Flags().Bool("preemptible", false, "Create a preemptible instance")
-	cmd.Flags().Bool("ephemeral", false, "Create an ephemeral instance")
-	cmd.Flags().String("type", "cpu.2x", "Instance type")
+	cmd.Flags().String("name", "", "Name of the instance; with --count greater than 1 a template such as 'worker-{{.Index}}'")
+	cmd.Flags().Int("count", 1, "Number of instances to create")
+	cmd.Flags().Bool("atomic", false, "Delete the created instances if any of them fails")
+	cmd.Flags().Bool("wait", false, "Wait for the instances to be running")
+	cmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time to wait")
+	cmd.Flags().String("template", "", "Name of a saved template to create the instance from")
+	cmd.Flags().Bool("print-user-data", false, "Print the generated user-data and exit")
+	addInstanceFlags(cmd.Flags())
+	cmd.RegisterFlagCompletionFunc("image", images.Complete)
+	cmd.RegisterFlagCompletionFunc("type", instancetype.Complete)
 
 	return &cmd
 }
 
-func parseFilesystemFlag(s string) (uuid.UUID, error) {
-	attrs := utils.ParseAttrs(s)
-	if id, found := attrs["id"]; found {
-		res, err := uuid.Parse(id)
-		if err != nil {
-			return uuid.Nil, fmt.Errorf("invalid filesystem id: %s", id)
-		}
-
-		return res, nil
-	} else {
-		return uuid.Nil, fmt.Errorf("missing 'id' attribute in filesystem: %s", s)
-	}
+// addInstanceFlags registers the flags describing the instance to create.
+// They are shared by create and templates save.
+func addInstanceFlags(flags *pflag.FlagSet) {
+	flags.StringArray("user-data", []string{}, "Path to cloud-init user-data (cloud-config, shell script or MIME multipart)")
+	sshkey.AddFlags(flags)
+	flags.Bool("no-project-keys", false, "Do not authorize the SSH keys stored in the local config for the project")
+	flags.StringArray("package", []string{}, "Package to install on first boot")
+	flags.StringArray("run-cmd", []string{}, "Command to run on first boot")
+	flags.StringArray("write-file", []string{}, "Local file to copy to the instance (in the format 'src:dest')")
+	flags.StringArray("env", []string{}, "Environment variable to set on the instance (in the format 'KEY=VALUE')")
+	flags.String("image", "", "Image name, alias or URL")
+	flags.StringArray("filesystem", []string{}, "Filesystem to attach (in the format 'id=<UUID>|name=<name>[,mount=<path>][,readonly=true]')")
+	flags.Bool("preemptible", false, "Create a preemptible instance")
+	flags.Bool("ephemeral", false, "Create an ephemeral instance")
+	flags.String("type", "cpu.2x", "Instance type")
 }
 
 func DeleteCommand() *cobra.Command {
@@ -0,0 +1,308 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/fluidstackio/fluidctl/internal/wait"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+const (
+	stateRunning    client.InstanceState = "running"
+	stateStopped    client.InstanceState = "stopped"
+	stateError      client.InstanceState = "error"
+	stateTerminated client.InstanceState = "terminated"
+)
+
+type response interface {
+	StatusCode() int
+	Status() string
+}
+
+type powerAction struct {
+	name     string
+	progress string
+	from     []client.InstanceState
+	target   client.InstanceState
+	do       func(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (response, error)
+
+	// restart is set for actions that end in the state they started from, so
+	// waiting has to see a new boot of the instance rather than the state.
+	restart bool
+}
+
+func StartCommand() *cobra.Command {
+	return powerCommand(powerAction{
+		name:     "start",
+		progress: "Starting",
+		from:     []client.InstanceState{stateStopped},
+		target:   stateRunning,
+		do: func(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (response, error) {
+			return c.PostInstancesIdStartWithResponse(ctx, id, &client.PostInstancesIdStartParams{
+				XPROJECTID: projectID,
+			})
+		},
+	})
+}
+
+func StopCommand() *cobra.Command {
+	return powerCommand(powerAction{
+		name:     "stop",
+		progress: "Stopping",
+		from:     []client.InstanceState{stateRunning},
+		target:   stateStopped,
+		do: func(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (response, error) {
+			return c.PostInstancesIdStopWithResponse(ctx, id, &client.PostInstancesIdStopParams{
+				XPROJECTID: projectID,
+			})
+		},
+	})
+}
+
+func RebootCommand() *cobra.Command {
+	return powerCommand(powerAction{
+		name:     "reboot",
+		progress: "Rebooting",
+		from:     []client.InstanceState{stateRunning},
+		target:   stateRunning,
+		restart:  true,
+		do: func(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (response, error) {
+			return c.PostInstancesIdRebootWithResponse(ctx, id, &client.PostInstancesIdRebootParams{
+				XPROJECTID: projectID,
+			})
+		},
+	})
+}
+
+func powerCommand(action powerAction) *cobra.Command {
+	cmd := cobra.Command{
+		Use:   action.name + " [id...]",
+		Short: action.name + " instances",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			all := utils.MustGetBoolFlag(cmd, "all")
+			pattern := utils.MustGetStringFlag(cmd, "name")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			if len(args) == 0 && !all && pattern == "" {
+				return fmt.Errorf("no instances specified: pass instance IDs, --name or --all")
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			instances, err := selectInstances(cmd.Context(), c, projectID, args, all, pattern)
+			if err != nil {
+				return err
+			}
+
+			errs := []error{}
+			accepted := []client.Instance{}
+			for _, instance := range instances {
+				if !slices.Contains(action.from, instance.State) {
+					errs = append(errs, fmt.Errorf("cannot %s instance %s: instance is %s", action.name, instance.Id, instance.State))
+					continue
+				}
+
+				res, err := action.do(cmd.Context(), c, projectID, instance.Id)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+
+				switch res.StatusCode() {
+				case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+				case http.StatusConflict:
+					errs = append(errs, fmt.Errorf("cannot %s instance %s in its current state: %s", action.name, instance.Id, res.Status()))
+					continue
+				default:
+					errs = append(errs, fmt.Errorf("failed to %s instance %s: %s", action.name, instance.Id, res.Status()))
+					continue
+				}
+
+				fmt.Printf("%s instance with ID: %s\n", action.progress, instance.Id)
+
+				accepted = append(accepted, instance)
+			}
+
+			if wait {
+				for _, instance := range accepted {
+					if err := waitForAction(cmd.Context(), c, projectID, instance, action, timeout); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			}
+
+			return errors.Join(errs...)
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Select all instances in the project")
+	cmd.Flags().String("name", "", "Select instances whose name matches the glob pattern")
+	cmd.Flags().Bool("wait", false, "Wait for the instances to reach the target state")
+	cmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time to wait")
+
+	return &cmd
+}
+
+// selectInstances resolves explicit instance IDs and the --all/--name
+// selectors into a de-duplicated list of instances.
+func selectInstances(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, ids []string, all bool, pattern string) ([]client.Instance, error) {
+	selected := []client.Instance{}
+	seen := map[uuid.UUID]bool{}
+
+	if all || pattern != "" {
+		res, err := c.GetInstancesWithResponse(ctx, &client.GetInstancesParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to list instances: %s", res.Status())
+		}
+
+		for _, instance := range *res.JSON200 {
+			if !all {
+				matched, err := path.Match(pattern, instance.Name)
+				if err != nil {
+					return nil, fmt.Errorf("invalid name pattern: %w", err)
+				}
+
+				if !matched {
+					continue
+				}
+			}
+
+			selected = append(selected, instance)
+			seen[instance.Id] = true
+		}
+
+		if len(selected) == 0 && len(ids) == 0 {
+			return nil, fmt.Errorf("no instances match the selection")
+		}
+	}
+
+	for _, s := range ids {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UUID: %w", err)
+		}
+
+		if seen[id] {
+			continue
+		}
+
+		res, err := c.GetInstancesIdWithResponse(ctx, id, &client.GetInstancesIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() == http.StatusNotFound {
+			return nil, fmt.Errorf("instance not found: %s", id)
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to get instance: %s", res.Status())
+		}
+
+		selected = append(selected, *res.JSON200)
+		seen[id] = true
+	}
+
+	return selected, nil
+}
+
+// waitForAction waits for the instance to reach the target state of the
+// action. A restart ends in the state it started from and can be over between
+// two polls, so it is done once the instance is in the target state with a
+// boot time later than the one before the action.
+func waitForAction(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, instance client.Instance, action powerAction, timeout time.Duration) error {
+	if !action.restart {
+		return waitForState(ctx, c, projectID, instance.Id, action.target, timeout)
+	}
+
+	fmt.Printf("Waiting for instance %s to be %s again\n", instance.Id, action.target)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("instance %s to be %s again", instance.Id, action.target), func(ctx context.Context) (bool, error) {
+		current, err := getInstance(ctx, c, projectID, instance.Id)
+		if err != nil {
+			return false, err
+		}
+
+		return current.State == action.target && bootedAfter(current, instance.StartedAt), nil
+	})
+}
+
+// bootedAfter reports whether the instance has booted after previous, the boot
+// time it had before. previous is nil if it had never booted.
+func bootedAfter(instance *client.Instance, previous *time.Time) bool {
+	if instance.StartedAt == nil {
+		return false
+	}
+
+	return previous == nil || instance.StartedAt.After(*previous)
+}
+
+// waitForState polls the instance until it reaches the target state, fails, or
+// the timeout expires.
+func waitForState(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, target client.InstanceState, timeout time.Duration) error {
+	fmt.Printf("Waiting for instance %s to be %s\n", id, target)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("instance %s to be %s", id, target), func(ctx context.Context) (bool, error) {
+		instance, err := getInstance(ctx, c, projectID, id)
+		return err == nil && instance.State == target, err
+	})
+}
+
+// getInstance returns the instance, or an error if the instance has failed.
+func getInstance(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID) (*client.Instance, error) {
+	res, err := c.GetInstancesIdWithResponse(ctx, id, &client.GetInstancesIdParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to get instance: %s", res.Status())
+	}
+
+	state := res.JSON200.State
+	if state == stateError || state == stateTerminated {
+		return nil, fmt.Errorf("instance %s is %s", id, state)
+	}
+
+	return res.JSON200, nil
+}
@@ -0,0 +1,35 @@
+package instance
+
+import (
+	"testing"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+)
+
+func TestBootedAfter(t *testing.T) {
+	before := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	after := before.Add(3 * time.Second)
+
+	tests := []struct {
+		name      string
+		startedAt *time.Time
+		previous  *time.Time
+		want      bool
+	}{
+		{name: "not booted", previous: &before, want: false},
+		{name: "same boot", startedAt: &before, previous: &before, want: false},
+		{name: "new boot", startedAt: &after, previous: &before, want: true},
+		{name: "first boot", startedAt: &after, want: true},
+		{name: "never booted", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &client.Instance{State: stateRunning, StartedAt: tt.startedAt}
+			if got := bootedAfter(instance, tt.previous); got != tt.want {
+				t.Errorf("bootedAfter() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
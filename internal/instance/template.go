@@ -0,0 +1,295 @@
+package instance
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fluidstackio/fluidctl/internal/config"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	images "github.com/fluidstackio/fluidctl/internal/image"
+	"github.com/fluidstackio/fluidctl/internal/instancetype"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TemplateCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "templates",
+		Short: "Manage instance templates",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		TemplateSaveCommand(),
+		TemplateListCommand(),
+		TemplateShowCommand(),
+		TemplateDeleteCommand(),
+	)
+
+	return &cmd
+}
+
+func TemplateSaveCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "save <name>",
+		Short: "save instance template",
+		Long:  "Save the given 'instances create' flags as a named template. An existing template with the same name is replaced.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			spec := pflag.NewFlagSet("", pflag.ContinueOnError)
+			addInstanceFlags(spec)
+
+			template := config.Template{}
+
+			var err error
+			cmd.Flags().Visit(func(f *pflag.Flag) {
+				if err != nil || spec.Lookup(f.Name) == nil {
+					return
+				}
+
+				if v, ok := f.Value.(pflag.SliceValue); ok {
+					values := []string{}
+					for _, value := range v.GetSlice() {
+						value, err = templatePath(f.Name, value)
+						if err != nil {
+							return
+						}
+
+						values = append(values, value)
+					}
+
+					template[f.Name] = values
+				} else {
+					template[f.Name] = f.Value.String()
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(template) == 0 {
+				return fmt.Errorf("no instance flags given")
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if cfg.Templates == nil {
+				cfg.Templates = map[string]config.Template{}
+			}
+			cfg.Templates[name] = template
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Saved template %q\n", name)
+
+			return nil
+		},
+	}
+
+	addInstanceFlags(cmd.Flags())
+	cmd.RegisterFlagCompletionFunc("image", images.Complete)
+	cmd.RegisterFlagCompletionFunc("type", instancetype.Complete)
+
+	return &cmd
+}
+
+// templatePath makes local paths in flag values absolute, so the template
+// works regardless of the directory create is run from.
+func templatePath(flag string, value string) (string, error) {
+	switch flag {
+	case "user-data", "ssh-authorized-key":
+		return absPath(value)
+	case "write-file":
+		i := strings.LastIndex(value, ":")
+		if i < 0 {
+			return value, nil
+		}
+
+		src, err := absPath(value[:i])
+		if err != nil {
+			return "", err
+		}
+
+		return src + value[i:], nil
+	default:
+		return value, nil
+	}
+}
+
+// absPath expands a leading ~, which the shell leaves alone in --flag=~/path,
+// before making the path absolute.
+func absPath(path string) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand path: %w", err)
+	}
+
+	return filepath.Abs(path)
+}
+
+func TemplateListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list instance templates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			names := []string{}
+			for name := range cfg.Templates {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(names)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func TemplateShowCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "show <name>",
+		Short: "show instance template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			template, ok := cfg.Templates[args[0]]
+			if !ok {
+				return fmt.Errorf("template not found: %s", args[0])
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(template)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func TemplateDeleteCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "delete <name>",
+		Short: "delete instance template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			if _, ok := cfg.Templates[args[0]]; !ok {
+				return fmt.Errorf("template not found: %s", args[0])
+			}
+
+			delete(cfg.Templates, args[0])
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Deleted template %q\n", args[0])
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+// applyTemplate sets every flag stored in the template that was not given
+// explicitly on the command line.
+func applyTemplate(cmd *cobra.Command, name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	template, ok := cfg.Templates[name]
+	if !ok {
+		return fmt.Errorf("template not found: %s", name)
+	}
+
+	for flag, value := range template {
+		f := cmd.Flags().Lookup(flag)
+		if f == nil {
+			return fmt.Errorf("template %s: unknown flag %q", name, flag)
+		}
+
+		if f.Changed {
+			continue
+		}
+
+		list, ok := value.([]interface{})
+		if !ok {
+			if err := cmd.Flags().Set(flag, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("template %s: invalid value for %q: %w", name, flag, err)
+			}
+			continue
+		}
+
+		// Lists are set as a whole, as Set would split values containing
+		// commas for slice flags.
+		v, ok := f.Value.(pflag.SliceValue)
+		if !ok {
+			return fmt.Errorf("template %s: flag %q does not take a list", name, flag)
+		}
+
+		values := []string{}
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+
+		if err := v.Replace(values); err != nil {
+			return fmt.Errorf("template %s: invalid value for %q: %w", name, flag, err)
+		}
+		f.Changed = true
+	}
+
+	return nil
+}
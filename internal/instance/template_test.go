@@ -0,0 +1,125 @@
+package instance
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/mitchellh/go-homedir"
+)
+
+// setHome points the home directory, and so the config file, at a temporary
+// directory.
+func setHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+
+	return home
+}
+
+func TestTemplatePath(t *testing.T) {
+	home := setHome(t)
+
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		flag  string
+		value string
+		want  string
+	}{
+		{name: "home", flag: "ssh-authorized-key", value: "~/.ssh/id.pub", want: filepath.Join(home, ".ssh/id.pub")},
+		{name: "relative", flag: "user-data", value: "init.yaml", want: filepath.Join(wd, "init.yaml")},
+		{name: "absolute", flag: "user-data", value: "/etc/init.yaml", want: "/etc/init.yaml"},
+		{name: "write-file source in home", flag: "write-file", value: "~/run.sh:/opt/run.sh", want: filepath.Join(home, "run.sh") + ":/opt/run.sh"},
+		{name: "write-file relative source", flag: "write-file", value: "run.sh:/opt/run.sh", want: filepath.Join(wd, "run.sh") + ":/opt/run.sh"},
+		{name: "not a path", flag: "package", value: "~/git", want: "~/git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := templatePath(tt.flag, tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("templatePath(%q, %q) = %q, want %q", tt.flag, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateRoundTrip(t *testing.T) {
+	home := setHome(t)
+
+	save := TemplateSaveCommand()
+	save.SetArgs([]string{
+		"trainer",
+		"--type=gpu.8x",
+		"--ssh-authorized-key=~/.ssh/id.pub",
+		"--filesystem=name=data,mount=/data,readonly=true",
+		"--filesystem=name=scratch",
+		"--package=git",
+		"--package=htop",
+	})
+	if err := save.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		flag string
+		want []string
+	}{
+		{name: "scalar", flag: "type", want: []string{"gpu.8x"}},
+		{name: "expanded path", flag: "ssh-authorized-key", want: []string{filepath.Join(home, ".ssh/id.pub")}},
+		{name: "values with commas", flag: "filesystem", want: []string{"name=data,mount=/data,readonly=true", "name=scratch"}},
+		{name: "list", flag: "package", want: []string{"git", "htop"}},
+		{name: "flag wins over scalar", args: []string{"--type=cpu.2x"}, flag: "type", want: []string{"cpu.2x"}},
+		{name: "flag replaces list", args: []string{"--package=vim"}, flag: "package", want: []string{"vim"}},
+		{name: "flag keeps other values", args: []string{"--package=vim"}, flag: "filesystem", want: []string{"name=data,mount=/data,readonly=true", "name=scratch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := CreateCommand()
+			if err := create.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := applyTemplate(create, "trainer"); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			if tt.flag == "type" {
+				got = []string{utils.MustGetStringFlag(create, tt.flag)}
+			} else {
+				got = utils.MustGetStringArrayFlag(create, tt.flag)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("--%s = %q, want %q", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTemplateNotFound(t *testing.T) {
+	setHome(t)
+
+	if err := applyTemplate(CreateCommand(), "missing"); err == nil {
+		t.Error("expected error for a missing template")
+	}
+}
@@ -0,0 +1,280 @@
+package instance
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+)
+
+type UserData struct {
+	SSHAuthorizedKeys []string    `yaml:"ssh_authorized_keys,omitempty"`
+	Packages          []string    `yaml:"packages,omitempty"`
+	RunCmd            []string    `yaml:"runcmd,omitempty"`
+	WriteFiles        []WriteFile `yaml:"write_files,omitempty"`
+	Mounts            [][]string  `yaml:"mounts,omitempty"`
+}
+
+type WriteFile struct {
+	Path     string `yaml:"path"`
+	Content  string `yaml:"content"`
+	Encoding string `yaml:"encoding,omitempty"`
+	Append   bool   `yaml:"append,omitempty"`
+}
+
+type userDataPart struct {
+	contentType string
+	content     []byte
+}
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// buildUserData merges the cloud-config generated from flags into the
+// user-data files. All cloud-config documents are merged into one, list keys
+// such as ssh_authorized_keys are concatenated. If any shell scripts or other
+// non cloud-config parts are present the result is a MIME multipart message.
+func buildUserData(paths []string, generated UserData) ([]byte, error) {
+	config := yaml.MapSlice{}
+	parts := []userDataPart{}
+
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read user-data file: %w", err)
+		}
+
+		fileParts, err := parseUserData(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user-data in %s: %w", p, err)
+		}
+
+		for _, part := range fileParts {
+			if part.contentType != "text/cloud-config" {
+				parts = append(parts, part)
+				continue
+			}
+
+			doc := yaml.MapSlice{}
+			if err := yaml.Unmarshal(part.content, &doc); err != nil {
+				return nil, fmt.Errorf("invalid cloud-config in %s: %w", p, err)
+			}
+
+			config, err = mergeCloudConfig(config, doc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge cloud-config from %s: %w", p, err)
+			}
+		}
+	}
+
+	b, err := yaml.Marshal(&generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user-data: %w", err)
+	}
+
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal user-data: %w", err)
+	}
+
+	config, err = mergeCloudConfig(config, doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge cloud-config: %w", err)
+	}
+
+	b = []byte("{}\n")
+	if len(config) != 0 {
+		b, err = yaml.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal user-data: %w", err)
+		}
+	}
+
+	cloudConfig := append([]byte("#cloud-config\n"), b...)
+	if len(parts) == 0 {
+		return cloudConfig, nil
+	}
+
+	parts = append([]userDataPart{{contentType: "text/cloud-config", content: cloudConfig}}, parts...)
+
+	return marshalMultipart(parts)
+}
+
+// parseUserData splits a user-data document into its parts based on the
+// format cloud-init would detect for it.
+func parseUserData(b []byte) ([]userDataPart, error) {
+	switch {
+	case bytes.HasPrefix(b, []byte("#cloud-config")):
+		return []userDataPart{{contentType: "text/cloud-config", content: b}}, nil
+	case bytes.HasPrefix(b, []byte("#!")):
+		return []userDataPart{{contentType: "text/x-shellscript", content: b}}, nil
+	case hasPrefixFold(b, "content-type:"), hasPrefixFold(b, "mime-version:"):
+		return parseMultipart(b)
+	default:
+		return nil, fmt.Errorf("unsupported format: expected #cloud-config, a #! script or MIME multipart")
+	}
+}
+
+func hasPrefixFold(b []byte, prefix string) bool {
+	return len(b) >= len(prefix) && strings.EqualFold(string(b[:len(prefix)]), prefix)
+}
+
+func parseMultipart(b []byte) ([]userDataPart, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MIME message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MIME content type: %w", err)
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("unsupported MIME content type: %s", mediaType)
+	}
+
+	parts := []userDataPart{}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MIME part: %w", err)
+		}
+
+		contentType, _, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MIME part content type: %w", err)
+		}
+
+		var body io.Reader = p
+		if strings.EqualFold(p.Header.Get("Content-Transfer-Encoding"), "base64") {
+			body = base64.NewDecoder(base64.StdEncoding, p)
+		}
+
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MIME part: %w", err)
+		}
+
+		parts = append(parts, userDataPart{contentType: contentType, content: content})
+	}
+
+	return parts, nil
+}
+
+func marshalMultipart(parts []userDataPart) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=\"%s\"\nMIME-Version: 1.0\n\n", w.Boundary())
+
+	for _, part := range parts {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {part.contentType + "; charset=\"utf-8\""},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write MIME part: %w", err)
+		}
+
+		if _, err := pw.Write(part.content); err != nil {
+			return nil, fmt.Errorf("failed to write MIME part: %w", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write MIME message: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mergeCloudConfig merges src into dst. Lists are concatenated, other keys
+// must either be absent from dst or have the same value.
+func mergeCloudConfig(dst yaml.MapSlice, src yaml.MapSlice) (yaml.MapSlice, error) {
+	for _, item := range src {
+		i := -1
+		for j := range dst {
+			if reflect.DeepEqual(dst[j].Key, item.Key) {
+				i = j
+				break
+			}
+		}
+
+		if i < 0 {
+			dst = append(dst, item)
+			continue
+		}
+
+		existing, ok := dst[i].Value.([]interface{})
+		added, addedOk := item.Value.([]interface{})
+		if ok && addedOk {
+			dst[i].Value = append(existing, added...)
+			continue
+		}
+
+		if !reflect.DeepEqual(dst[i].Value, item.Value) {
+			return nil, fmt.Errorf("conflicting values for %v", item.Key)
+		}
+	}
+
+	return dst, nil
+}
+
+func parseWriteFileFlag(s string) (WriteFile, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return WriteFile{}, fmt.Errorf("invalid write-file %q: expected 'src:dest'", s)
+	}
+
+	src, dest := s[:i], s[i+1:]
+	if !path.IsAbs(dest) {
+		return WriteFile{}, fmt.Errorf("invalid write-file %q: destination must be an absolute path", s)
+	}
+
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return WriteFile{}, fmt.Errorf("failed to read write-file source: %w", err)
+	}
+
+	if utf8.Valid(content) {
+		return WriteFile{Path: dest, Content: string(content)}, nil
+	}
+
+	return WriteFile{
+		Path:     dest,
+		Content:  base64.StdEncoding.EncodeToString(content),
+		Encoding: "b64",
+	}, nil
+}
+
+func parseEnvFlag(s string) (string, error) {
+	name, value, found := strings.Cut(s, "=")
+	if !found {
+		return "", fmt.Errorf("invalid env %q: expected 'KEY=VALUE'", s)
+	}
+
+	if !envNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("invalid env %q: %q is not a valid variable name", s, name)
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return "", fmt.Errorf("invalid env %q: value must not contain newlines", s)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,195 @@
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeCloudConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty destination",
+			dst:  "{}",
+			src:  "packages: [git]",
+			want: "packages: [git]",
+		},
+		{
+			name: "lists are concatenated",
+			dst:  "ssh_authorized_keys: [ssh-ed25519 AAAA a]\npackages: [git]",
+			src:  "ssh_authorized_keys: [ssh-ed25519 BBBB b]",
+			want: "ssh_authorized_keys: [ssh-ed25519 AAAA a, ssh-ed25519 BBBB b]\npackages: [git]",
+		},
+		{
+			name: "new keys are appended in order",
+			dst:  "packages: [git]",
+			src:  "timezone: UTC\nruncmd: [reboot]",
+			want: "packages: [git]\ntimezone: UTC\nruncmd: [reboot]",
+		},
+		{
+			name: "equal scalars are kept",
+			dst:  "timezone: UTC",
+			src:  "timezone: UTC",
+			want: "timezone: UTC",
+		},
+		{
+			name:    "conflicting scalars",
+			dst:     "timezone: UTC",
+			src:     "timezone: Europe/London",
+			wantErr: true,
+		},
+		{
+			name:    "list and scalar",
+			dst:     "packages: [git]",
+			src:     "packages: git",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeCloudConfig(unmarshalMapSlice(t, tt.dst), unmarshalMapSlice(t, tt.src))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := unmarshalMapSlice(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBuildUserData(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		generated UserData
+		want      []string
+		multipart bool
+		wantErr   bool
+	}{
+		{
+			name: "no input",
+			want: []string{"#cloud-config\n{}\n"},
+		},
+		{
+			name:      "generated only",
+			generated: UserData{SSHAuthorizedKeys: []string{"ssh-ed25519 AAAA a"}},
+			want:      []string{"#cloud-config\n", "ssh_authorized_keys:\n- ssh-ed25519 AAAA a\n"},
+		},
+		{
+			name:      "file and generated keys are merged",
+			files:     []string{"#cloud-config\nssh_authorized_keys: [ssh-ed25519 BBBB b]\npackages: [git]\n"},
+			generated: UserData{SSHAuthorizedKeys: []string{"ssh-ed25519 AAAA a"}},
+			want:      []string{"ssh_authorized_keys:\n- ssh-ed25519 BBBB b\n- ssh-ed25519 AAAA a\npackages:\n- git\n"},
+		},
+		{
+			name:      "mounts",
+			generated: UserData{Mounts: [][]string{{"0b8f6e0c-2f4e-4d39-9a4a-55a6d9c3f1e2", "/mnt/data", "virtiofs", "defaults,nofail", "0", "0"}}},
+			want:      []string{"mounts:\n- - 0b8f6e0c-2f4e-4d39-9a4a-55a6d9c3f1e2\n  - /mnt/data\n  - virtiofs\n"},
+		},
+		{
+			name:      "script becomes a multipart message",
+			files:     []string{"#!/bin/sh\necho hello\n"},
+			generated: UserData{Packages: []string{"git"}},
+			want:      []string{"Content-Type: multipart/mixed", "text/cloud-config", "packages:\n- git", "text/x-shellscript", "echo hello"},
+			multipart: true,
+		},
+		{
+			name:    "conflicting files",
+			files:   []string{"#cloud-config\ntimezone: UTC\n", "#cloud-config\ntimezone: Europe/London\n"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported format",
+			files:   []string{"packages: [git]\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths := []string{}
+			for i, content := range tt.files {
+				p := filepath.Join(dir, string(rune('a'+i)))
+				if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				paths = append(paths, p)
+			}
+
+			b, err := buildUserData(paths, tt.generated)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := string(b)
+			if multipart := strings.HasPrefix(got, "Content-Type:"); multipart != tt.multipart {
+				t.Errorf("multipart = %t, want %t", multipart, tt.multipart)
+			}
+
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("user-data does not contain %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEnvFlag(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: "FOO=bar"},
+		{in: "_FOO1="},
+		{in: "FOO=a=b"},
+		{in: "FOO", wantErr: true},
+		{in: "1FOO=bar", wantErr: true},
+		{in: "FOO-BAR=baz", wantErr: true},
+		{in: "FOO=a\nb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := parseEnvFlag(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseEnvFlag(%q) error = %v, wantErr %t", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func unmarshalMapSlice(t *testing.T, s string) yaml.MapSlice {
+	t.Helper()
+
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
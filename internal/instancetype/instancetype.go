@@ -0,0 +1,269 @@
+package instancetype
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "instance-types",
+		Short: "Browse instance types, capacity and pricing",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		ListCommand(),
+	)
+
+	return &cmd
+}
+
+func ListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list instance types",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			gpu := utils.MustGetStringFlag(cmd, "gpu")
+			minGPUs := utils.MustGetIntFlag(cmd, "min-gpus")
+			region := utils.MustGetStringFlag(cmd, "region")
+			available := utils.MustGetBoolFlag(cmd, "available")
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			instanceTypes, err := List(cmd.Context(), c)
+			if err != nil {
+				return err
+			}
+
+			filtered := filter(instanceTypes, gpu, minGPUs, region, available)
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(instanceTypeTable(filtered))
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("gpu", "", "Only show types with this GPU model (e.g. h100)")
+	cmd.Flags().Int("min-gpus", 0, "Only show types with at least this many GPUs")
+	cmd.Flags().String("region", "", "Only show availability in this region")
+	cmd.Flags().Bool("available", false, "Only show types with capacity available")
+
+	return &cmd
+}
+
+// filter returns the instance types matching the list flags. Types left
+// without availability by the region and available filters are dropped.
+func filter(instanceTypes []client.InstanceType, gpu string, minGPUs int, region string, available bool) []client.InstanceType {
+	filtered := []client.InstanceType{}
+	for _, t := range instanceTypes {
+		if gpu != "" && (t.Gpu == nil || !strings.Contains(strings.ToLower(t.Gpu.Model), strings.ToLower(gpu))) {
+			continue
+		}
+
+		if minGPUs > 0 && (t.Gpu == nil || t.Gpu.Count < minGPUs) {
+			continue
+		}
+
+		if region != "" || available {
+			t = filterAvailability(t, region, available)
+			if t.Availability == nil || len(*t.Availability) == 0 {
+				continue
+			}
+		}
+
+		filtered = append(filtered, t)
+	}
+
+	return filtered
+}
+
+// filterAvailability returns t with its availability restricted to the region
+// and, if available is set, to regions with free capacity.
+func filterAvailability(t client.InstanceType, region string, available bool) client.InstanceType {
+	if t.Availability == nil {
+		return t
+	}
+
+	availability := []client.InstanceTypeAvailability{}
+	for _, a := range *t.Availability {
+		if region != "" && a.Region != region {
+			continue
+		}
+
+		if available && a.Available == 0 {
+			continue
+		}
+
+		availability = append(availability, a)
+	}
+
+	t.Availability = &availability
+
+	return t
+}
+
+func List(ctx context.Context, c *client.ClientWithResponses) ([]client.InstanceType, error) {
+	res, err := c.GetInstanceTypesWithResponse(ctx, &client.GetInstanceTypesParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list instance types: %s", res.Status())
+	}
+
+	return *res.JSON200, nil
+}
+
+// Validate checks that name is a known instance type. If the catalog is
+// unavailable the check is skipped with a warning and the API has the final
+// say. Other failures, such as a rejected token, are returned.
+func Validate(ctx context.Context, c *client.ClientWithResponses, name string) error {
+	res, err := c.GetInstanceTypesWithResponse(ctx, &client.GetInstanceTypesParams{})
+	if err != nil {
+		if !catalogUnavailable(ctx, err, 0) {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Warning: could not check instance type %q: %v\n", name, err)
+		return nil
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		if !catalogUnavailable(ctx, nil, res.StatusCode()) {
+			return fmt.Errorf("failed to list instance types: %s", res.Status())
+		}
+
+		fmt.Fprintf(os.Stderr, "Warning: could not check instance type %q: %s\n", name, res.Status())
+		return nil
+	}
+
+	for _, t := range *res.JSON200 {
+		if t.Name == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown instance type %q, see 'fluidctl instance-types list'", name)
+}
+
+// catalogUnavailable reports whether listing the catalog failed because it
+// could not be reached, it is missing or the server failed. Cancellation and
+// client errors such as 401 and 403 are not an outage.
+func catalogUnavailable(ctx context.Context, err error, statusCode int) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	return statusCode == http.StatusNotFound || statusCode >= 500
+}
+
+type instanceTypeTable []client.InstanceType
+
+func (t instanceTypeTable) Header() []string {
+	return []string{"NAME", "CPU", "MEMORY", "GPU", "GPUS", "PRICE/HOUR", "AVAILABILITY"}
+}
+
+func (t instanceTypeTable) Rows() [][]string {
+	rows := [][]string{}
+	for _, it := range t {
+		gpu, gpus := "", ""
+		if it.Gpu != nil {
+			gpu, gpus = it.Gpu.Model, strconv.Itoa(it.Gpu.Count)
+		}
+
+		availability := []string{}
+		if it.Availability != nil {
+			for _, a := range *it.Availability {
+				availability = append(availability, fmt.Sprintf("%s=%d", a.Region, a.Available))
+			}
+		}
+
+		rows = append(rows, []string{
+			it.Name,
+			strconv.Itoa(it.Cpu),
+			it.Memory,
+			gpu,
+			gpus,
+			fmt.Sprintf("$%.2f", it.PricePerHour),
+			strings.Join(availability, ","),
+		})
+	}
+
+	return rows
+}
+
+// Complete completes instance type names. It only uses cached credentials so
+// that completion never starts an interactive login.
+func Complete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	url := utils.MustGetStringFlag(cmd, "url")
+
+	token, err := auth.CachedLogin(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	instanceTypes, err := List(cmd.Context(), c)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := []string{}
+	for _, t := range instanceTypes {
+		description := fmt.Sprintf("%d CPU, %s", t.Cpu, t.Memory)
+		if t.Gpu != nil {
+			description += fmt.Sprintf(", %dx %s", t.Gpu.Count, t.Gpu.Model)
+		}
+
+		completions = append(completions, t.Name+"\t"+description)
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,102 @@
+package instancetype
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+)
+
+func TestFilter(t *testing.T) {
+	instanceTypes := []client.InstanceType{
+		{Name: "cpu.2x", Cpu: 2, Availability: &[]client.InstanceTypeAvailability{{Region: "eu-west", Available: 10}}},
+		{Name: "h100.1x", Cpu: 16, Gpu: &client.InstanceTypeGpu{Model: "H100-SXM", Count: 1}, Availability: &[]client.InstanceTypeAvailability{{Region: "eu-west", Available: 0}, {Region: "us-east", Available: 4}}},
+		{Name: "h100.8x", Cpu: 128, Gpu: &client.InstanceTypeGpu{Model: "H100-SXM", Count: 8}, Availability: &[]client.InstanceTypeAvailability{{Region: "eu-west", Available: 2}}},
+		{Name: "a100.8x", Cpu: 96, Gpu: &client.InstanceTypeGpu{Model: "A100", Count: 8}},
+	}
+
+	tests := []struct {
+		name      string
+		gpu       string
+		minGPUs   int
+		region    string
+		available bool
+		want      []string
+	}{
+		{name: "no filter", want: []string{"cpu.2x", "h100.1x", "h100.8x", "a100.8x"}},
+		{name: "gpu model is case insensitive", gpu: "h100", want: []string{"h100.1x", "h100.8x"}},
+		{name: "gpu model substring", gpu: "SXM", want: []string{"h100.1x", "h100.8x"}},
+		{name: "min gpus", minGPUs: 8, want: []string{"h100.8x", "a100.8x"}},
+		{name: "gpu and min gpus", gpu: "h100", minGPUs: 2, want: []string{"h100.8x"}},
+		{name: "region", region: "us-east", want: []string{"h100.1x"}},
+		{name: "available", available: true, want: []string{"cpu.2x", "h100.1x", "h100.8x"}},
+		{name: "available in region", region: "eu-west", available: true, want: []string{"cpu.2x", "h100.8x"}},
+		{name: "no match", gpu: "mi300", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, it := range filter(instanceTypes, tt.gpu, tt.minGPUs, tt.region, tt.available) {
+				got = append(got, it.Name)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterAvailability(t *testing.T) {
+	it := client.InstanceType{
+		Name:         "h100.1x",
+		Availability: &[]client.InstanceTypeAvailability{{Region: "eu-west", Available: 0}, {Region: "us-east", Available: 4}},
+	}
+
+	got := filterAvailability(it, "", true)
+	if want := []client.InstanceTypeAvailability{{Region: "us-east", Available: 4}}; !slices.Equal(*got.Availability, want) {
+		t.Errorf("got %v, want %v", *got.Availability, want)
+	}
+
+	if len(*it.Availability) != 2 {
+		t.Errorf("the original availability was modified: %v", *it.Availability)
+	}
+
+	if got := filterAvailability(client.InstanceType{Name: "cpu.2x"}, "eu-west", true); got.Availability != nil {
+		t.Errorf("got availability %v for a type without any", *got.Availability)
+	}
+}
+
+func TestCatalogUnavailable(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		statusCode int
+		want       bool
+	}{
+		{name: "transport error", ctx: context.Background(), err: errors.New("connection refused"), want: true},
+		{name: "canceled", ctx: canceled, err: context.Canceled, want: false},
+		{name: "not found", ctx: context.Background(), statusCode: http.StatusNotFound, want: true},
+		{name: "server error", ctx: context.Background(), statusCode: http.StatusInternalServerError, want: true},
+		{name: "bad gateway", ctx: context.Background(), statusCode: http.StatusBadGateway, want: true},
+		{name: "unauthorized", ctx: context.Background(), statusCode: http.StatusUnauthorized, want: false},
+		{name: "forbidden", ctx: context.Background(), statusCode: http.StatusForbidden, want: false},
+		{name: "bad request", ctx: context.Background(), statusCode: http.StatusBadRequest, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalogUnavailable(tt.ctx, tt.err, tt.statusCode); got != tt.want {
+				t.Errorf("catalogUnavailable() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
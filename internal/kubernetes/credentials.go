@@ -0,0 +1,248 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+const execAPIVersion = "client.authentication.k8s.io/v1"
+
+// execCredential is the credential plugin output kubectl reads.
+type execCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	Token               string     `json:"token"`
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
+
+func GetCredentialsCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "get-credentials <cluster>",
+		Short: "add kubernetes cluster credentials to kubeconfig",
+		Long: `Download the kubeconfig of the kubernetes cluster given by name or ID and
+merge it into ~/.kube/config, or the first file in $KUBECONFIG, as the context
+fluidstack-<cluster>.
+
+With --exec-plugin kubectl gets its token from 'fluidctl kubernetes
+credential-plugin' instead of storing one in the kubeconfig, so access to the
+cluster follows your fluidctl login.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			execPlugin := utils.MustGetBoolFlag(cmd, "exec-plugin")
+			setCurrent := utils.MustGetBoolFlag(cmd, "set-current")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			path, err := kubeconfigPath(utils.MustGetStringFlag(cmd, "kubeconfig"))
+			if err != nil {
+				return fmt.Errorf("failed to expand path: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			cluster, err := lookupCluster(cmd.Context(), c, projectID, args[0])
+			if err != nil {
+				return err
+			}
+
+			res, err := c.GetKubernetesClustersIdKubeconfigWithResponse(cmd.Context(), cluster.Id, &client.GetKubernetesClustersIdKubeconfigParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() == http.StatusConflict {
+				return fmt.Errorf("kubernetes cluster %s is %s, try again once it is %s", cluster.Name, cluster.State, stateRunning)
+			}
+
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+				return fmt.Errorf("failed to get kubeconfig: %s", res.Status())
+			}
+
+			downloaded, err := parseKubeconfig([]byte(res.JSON200.Kubeconfig))
+			if err != nil {
+				return err
+			}
+
+			current, err := downloaded.currentContext()
+			if err != nil {
+				return err
+			}
+
+			clusterEntry, err := downloaded.cluster(current.Context.Cluster)
+			if err != nil {
+				return err
+			}
+
+			user := map[string]interface{}{}
+			if execPlugin {
+				executable, err := os.Executable()
+				if err != nil {
+					return fmt.Errorf("failed to find fluidctl executable: %w", err)
+				}
+
+				user["exec"] = execConfig{
+					APIVersion:      execAPIVersion,
+					Command:         executable,
+					Args:            []string{"kubernetes", "credential-plugin"},
+					InteractiveMode: "IfAvailable",
+				}
+			} else {
+				userEntry, err := downloaded.user(current.Context.User)
+				if err != nil {
+					return err
+				}
+
+				user = userEntry.User
+			}
+
+			config, err := readKubeconfig(path)
+			if err != nil {
+				return err
+			}
+
+			name := "fluidstack-" + cluster.Name
+			config.merge(name, clusterEntry.Cluster, user)
+			if setCurrent {
+				config.CurrentContext = name
+			}
+
+			if err := writeKubeconfig(path, config); err != nil {
+				return fmt.Errorf("failed to write kubeconfig: %w", err)
+			}
+
+			fmt.Printf("Added context %s to %s\n", name, path)
+			if setCurrent {
+				fmt.Printf("Switched to context %s\n", name)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("kubeconfig", "", "Kubeconfig file to merge into (default $KUBECONFIG or ~/.kube/config)")
+	cmd.Flags().Bool("exec-plugin", false, "Authenticate with fluidctl's login instead of a static token")
+	cmd.Flags().Bool("set-current", true, "Switch the current context to the cluster")
+
+	return &cmd
+}
+
+func CredentialPluginCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "credential-plugin",
+		Short: "kubectl credential plugin",
+		Long: `Print an ExecCredential with the fluidctl token for kubectl. This is run by
+kubectl for contexts added with 'fluidctl kubernetes clusters get-credentials
+--exec-plugin' and is not meant to be run directly.
+
+The token comes from the same cache as every other command and is refreshed
+when it has expired. The plugin never opens a browser; if the token cannot be
+refreshed it fails and asks you to run 'fluidctl login'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := auth.CachedLogin(cmd)
+			if err != nil {
+				// kubectl reads the credential from stdout and shows stderr to
+				// the user, so the error must only go to stderr.
+				fmt.Fprintf(os.Stderr, "fluidctl: failed to get a token: %v\nRun 'fluidctl login' and try again.\n", err)
+
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return &utils.ExitError{Code: 1}
+			}
+
+			credential := execCredential{
+				APIVersion: execAPIVersion,
+				Kind:       "ExecCredential",
+				Status: execCredentialStatus{
+					Token: token,
+				},
+			}
+
+			// Tokens given with --token need not be JWTs. Without an expiry
+			// kubectl uses the token until the cluster rejects it.
+			if expiry, err := auth.TokenExpiry(token); err == nil {
+				expiry = expiry.UTC()
+				credential.Status.ExpirationTimestamp = &expiry
+			}
+
+			b, err := json.Marshal(credential)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+// lookupCluster finds a cluster by name or ID.
+func lookupCluster(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, ref string) (*client.KubernetesCluster, error) {
+	res, err := c.GetKubernetesClustersWithResponse(ctx, &client.GetKubernetesClustersParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list clusters: %s", res.Status())
+	}
+
+	var found *client.KubernetesCluster
+	for _, cluster := range *res.JSON200 {
+		if cluster.Id.String() == ref {
+			return &cluster, nil
+		}
+
+		if cluster.Name == ref {
+			if found != nil {
+				return nil, fmt.Errorf("kubernetes cluster name %q is ambiguous, use the ID instead", ref)
+			}
+
+			found = &cluster
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("kubernetes cluster not found: %s", ref)
+	}
+
+	return found, nil
+}
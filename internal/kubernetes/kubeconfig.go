@@ -0,0 +1,210 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultKubeconfig = "~/.kube/config"
+
+// kubeconfig is a kubectl config file. Only the fields fluidctl changes are
+// typed; everything else is kept as is when the file is rewritten.
+type kubeconfig struct {
+	APIVersion     string                 `yaml:"apiVersion"`
+	Kind           string                 `yaml:"kind"`
+	Clusters       []namedCluster         `yaml:"clusters"`
+	Contexts       []namedContext         `yaml:"contexts"`
+	Users          []namedUser            `yaml:"users"`
+	CurrentContext string                 `yaml:"current-context"`
+	Extra          map[string]interface{} `yaml:",inline"`
+}
+
+type namedCluster struct {
+	Name    string                 `yaml:"name"`
+	Cluster map[string]interface{} `yaml:"cluster"`
+}
+
+type namedContext struct {
+	Name    string      `yaml:"name"`
+	Context kubeContext `yaml:"context"`
+}
+
+type kubeContext struct {
+	Cluster   string                 `yaml:"cluster"`
+	User      string                 `yaml:"user"`
+	Namespace string                 `yaml:"namespace,omitempty"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+type namedUser struct {
+	Name string                 `yaml:"name"`
+	User map[string]interface{} `yaml:"user"`
+}
+
+// execConfig runs a credential plugin to get a token for the user.
+type execConfig struct {
+	APIVersion      string   `yaml:"apiVersion"`
+	Command         string   `yaml:"command"`
+	Args            []string `yaml:"args"`
+	InteractiveMode string   `yaml:"interactiveMode"`
+}
+
+// kubeconfigPath returns the file to merge credentials into: the given path,
+// else the first file in $KUBECONFIG, else ~/.kube/config. kubectl writes to
+// the first file in $KUBECONFIG too.
+func kubeconfigPath(path string) (string, error) {
+	if path == "" {
+		for _, p := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+			if p != "" {
+				path = p
+				break
+			}
+		}
+	}
+
+	if path == "" {
+		path = defaultKubeconfig
+	}
+
+	return homedir.Expand(path)
+}
+
+func parseKubeconfig(b []byte) (*kubeconfig, error) {
+	config := kubeconfig{}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	return &config, nil
+}
+
+// readKubeconfig reads the kubeconfig at path. A missing file is an empty
+// config.
+func readKubeconfig(path string) (*kubeconfig, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &kubeconfig{APIVersion: "v1", Kind: "Config"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseKubeconfig(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if config.APIVersion == "" {
+		config.APIVersion = "v1"
+	}
+	if config.Kind == "" {
+		config.Kind = "Config"
+	}
+
+	return config, nil
+}
+
+func writeKubeconfig(path string, config *kubeconfig) error {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+
+	return os.WriteFile(path, b, 0600)
+}
+
+// currentContext returns the current context of the config, or its only
+// context if none is set.
+func (k *kubeconfig) currentContext() (*namedContext, error) {
+	if i := k.indexContext(k.CurrentContext); i >= 0 {
+		return &k.Contexts[i], nil
+	}
+
+	if k.CurrentContext == "" && len(k.Contexts) == 1 {
+		return &k.Contexts[0], nil
+	}
+
+	return nil, fmt.Errorf("kubeconfig has no current context")
+}
+
+func (k *kubeconfig) cluster(name string) (*namedCluster, error) {
+	if i := k.indexCluster(name); i >= 0 {
+		return &k.Clusters[i], nil
+	}
+
+	return nil, fmt.Errorf("kubeconfig has no cluster %q", name)
+}
+
+func (k *kubeconfig) user(name string) (*namedUser, error) {
+	if i := k.indexUser(name); i >= 0 {
+		return &k.Users[i], nil
+	}
+
+	return nil, fmt.Errorf("kubeconfig has no user %q", name)
+}
+
+// merge adds the cluster, user and a context using them under name,
+// replacing any entries of the same name.
+func (k *kubeconfig) merge(name string, cluster map[string]interface{}, user map[string]interface{}) {
+	c := namedCluster{Name: name, Cluster: cluster}
+	if i := k.indexCluster(name); i >= 0 {
+		k.Clusters[i] = c
+	} else {
+		k.Clusters = append(k.Clusters, c)
+	}
+
+	u := namedUser{Name: name, User: user}
+	if i := k.indexUser(name); i >= 0 {
+		k.Users[i] = u
+	} else {
+		k.Users = append(k.Users, u)
+	}
+
+	ctx := namedContext{Name: name, Context: kubeContext{Cluster: name, User: name}}
+	if i := k.indexContext(name); i >= 0 {
+		// Keep the namespace and anything else set on the context.
+		ctx.Context.Namespace = k.Contexts[i].Context.Namespace
+		ctx.Context.Extra = k.Contexts[i].Context.Extra
+		k.Contexts[i] = ctx
+	} else {
+		k.Contexts = append(k.Contexts, ctx)
+	}
+}
+
+func (k *kubeconfig) indexCluster(name string) int {
+	for i, c := range k.Clusters {
+		if c.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (k *kubeconfig) indexUser(name string) int {
+	for i, u := range k.Users {
+		if u.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (k *kubeconfig) indexContext(name string) int {
+	for i, c := range k.Contexts {
+		if c.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,268 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const existingKubeconfig = `apiVersion: v1
+kind: Config
+preferences:
+  colors: true
+clusters:
+- name: other
+  cluster:
+    server: https://other.example.com
+- name: fluidstack-training
+  cluster:
+    server: https://old.example.com
+contexts:
+- name: other
+  context:
+    cluster: other
+    user: other
+- name: fluidstack-training
+  context:
+    cluster: fluidstack-training
+    user: fluidstack-training
+    namespace: jobs
+users:
+- name: other
+  user:
+    token: other-token
+- name: fluidstack-training
+  user:
+    token: old-token
+current-context: other
+`
+
+func TestKubeconfigMerge(t *testing.T) {
+	cluster := map[string]interface{}{"server": "https://new.example.com"}
+	user := map[string]interface{}{"token": "new-token"}
+
+	tests := []struct {
+		name      string
+		config    string
+		entry     string
+		clusters  []string
+		users     []string
+		contexts  []string
+		namespace string
+	}{
+		{
+			name:     "empty config",
+			config:   "",
+			entry:    "fluidstack-training",
+			clusters: []string{"fluidstack-training"},
+			users:    []string{"fluidstack-training"},
+			contexts: []string{"fluidstack-training"},
+		},
+		{
+			name:     "new entry is appended",
+			config:   existingKubeconfig,
+			entry:    "fluidstack-inference",
+			clusters: []string{"other", "fluidstack-training", "fluidstack-inference"},
+			users:    []string{"other", "fluidstack-training", "fluidstack-inference"},
+			contexts: []string{"other", "fluidstack-training", "fluidstack-inference"},
+		},
+		{
+			name:      "existing entry is replaced in place",
+			config:    existingKubeconfig,
+			entry:     "fluidstack-training",
+			clusters:  []string{"other", "fluidstack-training"},
+			users:     []string{"other", "fluidstack-training"},
+			contexts:  []string{"other", "fluidstack-training"},
+			namespace: "jobs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseKubeconfig([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config.merge(tt.entry, cluster, user)
+
+			if got := clusterNames(config); !reflect.DeepEqual(got, tt.clusters) {
+				t.Errorf("clusters = %v, want %v", got, tt.clusters)
+			}
+			if got := userNames(config); !reflect.DeepEqual(got, tt.users) {
+				t.Errorf("users = %v, want %v", got, tt.users)
+			}
+			if got := contextNames(config); !reflect.DeepEqual(got, tt.contexts) {
+				t.Errorf("contexts = %v, want %v", got, tt.contexts)
+			}
+
+			c, err := config.cluster(tt.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.Cluster, cluster) {
+				t.Errorf("cluster = %v, want %v", c.Cluster, cluster)
+			}
+
+			u, err := config.user(tt.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(u.User, user) {
+				t.Errorf("user = %v, want %v", u.User, user)
+			}
+
+			ctx := config.Contexts[config.indexContext(tt.entry)].Context
+			if ctx.Cluster != tt.entry || ctx.User != tt.entry || ctx.Namespace != tt.namespace {
+				t.Errorf("context = %+v, want cluster and user %s and namespace %q", ctx, tt.entry, tt.namespace)
+			}
+		})
+	}
+}
+
+func TestKubeconfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kube", "config")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(existingKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := readKubeconfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.merge("fluidstack-training", map[string]interface{}{"server": "https://new.example.com"}, map[string]interface{}{"token": "new-token"})
+
+	if err := writeKubeconfig(path, config); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"preferences:", "colors: true", "other-token", "new-token", "namespace: jobs", "current-context: other"} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("rewritten kubeconfig does not contain %q:\n%s", s, b)
+		}
+	}
+
+	if strings.Contains(string(b), "old-token") {
+		t.Errorf("rewritten kubeconfig still contains the old token:\n%s", b)
+	}
+}
+
+func TestReadKubeconfigMissing(t *testing.T) {
+	config, err := readKubeconfig(filepath.Join(t.TempDir(), "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.APIVersion != "v1" || config.Kind != "Config" {
+		t.Errorf("got %s %s, want v1 Config", config.APIVersion, config.Kind)
+	}
+}
+
+func TestCurrentContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  kubeconfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "current context set",
+			config: kubeconfig{CurrentContext: "b", Contexts: []namedContext{{Name: "a"}, {Name: "b"}}},
+			want:   "b",
+		},
+		{
+			name:   "only context",
+			config: kubeconfig{Contexts: []namedContext{{Name: "a"}}},
+			want:   "a",
+		},
+		{
+			name:    "several contexts and none current",
+			config:  kubeconfig{Contexts: []namedContext{{Name: "a"}, {Name: "b"}}},
+			wantErr: true,
+		},
+		{
+			name:    "current context missing",
+			config:  kubeconfig{CurrentContext: "c", Contexts: []namedContext{{Name: "a"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := tt.config.currentContext()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", ctx.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ctx.Name != tt.want {
+				t.Errorf("got %s, want %s", ctx.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubeconfigPath(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv("KUBECONFIG", string(filepath.ListSeparator)+filepath.Join(dir, "a")+string(filepath.ListSeparator)+filepath.Join(dir, "b"))
+
+	got, err := kubeconfigPath("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "a"); got != want {
+		t.Errorf("kubeconfigPath() = %s, want %s", got, want)
+	}
+
+	got, err = kubeconfigPath(filepath.Join(dir, "explicit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "explicit"); got != want {
+		t.Errorf("kubeconfigPath(explicit) = %s, want %s", got, want)
+	}
+}
+
+func clusterNames(k *kubeconfig) []string {
+	names := []string{}
+	for _, c := range k.Clusters {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
+func userNames(k *kubeconfig) []string {
+	names := []string{}
+	for _, u := range k.Users {
+		names = append(names, u.Name)
+	}
+
+	return names
+}
+
+func contextNames(k *kubeconfig) []string {
+	names := []string{}
+	for _, c := range k.Contexts {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
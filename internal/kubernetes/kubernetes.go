@@ -3,10 +3,13 @@ package kubernetes
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
 	"github.com/fluidstackio/fluidctl/internal/auth"
 	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/instancetype"
 	"github.com/fluidstackio/fluidctl/internal/utils"
 	"github.com/google/uuid"
 	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
@@ -26,6 +29,8 @@ func Command() *cobra.Command {
 
 	cmd.AddCommand(
 		ClusterCommand(),
+		NodePoolCommand(),
+		CredentialPluginCommand(),
 	)
 
 	return &cmd
@@ -41,7 +46,12 @@ func ClusterCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		CreateCommand(),
+		DeleteCommand(),
 		ListCommand(),
+		DescribeCommand(),
+		UpgradeCommand(),
+		GetCredentialsCommand(),
 	)
 
 	return &cmd
@@ -102,3 +112,323 @@ func ListCommand() *cobra.Command {
 
 	return &cmd
 }
+
+func CreateCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "create",
+		Short: "create kubernetes cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			name := utils.MustGetStringFlag(cmd, "name")
+			version := utils.MustGetStringFlag(cmd, "version")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			nodePools := []client.KubernetesNodePoolSpec{}
+			for i, s := range utils.MustGetStringArrayFlag(cmd, "node-pool") {
+				nodePool, err := parseNodePoolFlag(s, i)
+				if err != nil {
+					return err
+				}
+
+				nodePools = append(nodePools, nodePool)
+			}
+
+			if len(nodePools) == 0 {
+				return fmt.Errorf("at least one --node-pool is required")
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			for _, nodePool := range nodePools {
+				if err := instancetype.Validate(cmd.Context(), c, nodePool.Type); err != nil {
+					return err
+				}
+			}
+
+			cluster := client.KubernetesClustersPostRequest{
+				Name:      name,
+				NodePools: nodePools,
+			}
+			if version != "" {
+				cluster.Version = &version
+			}
+
+			res, err := c.PostKubernetesClustersWithResponse(cmd.Context(), &client.PostKubernetesClustersParams{
+				XPROJECTID: projectID,
+			}, cluster)
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+				return fmt.Errorf("failed to create cluster: %s", res.Status())
+			}
+
+			id := res.JSON201.Id
+			fmt.Printf("Creating kubernetes cluster %s with ID: %s\n", name, id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForCluster(cmd.Context(), c, projectID, id, "", timeout)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Name of the cluster")
+	cmd.Flags().String("version", "", "Kubernetes version (default the latest supported version)")
+	cmd.Flags().StringArray("node-pool", []string{}, "Node pool (in the format 'type=<type>[,count=<n>][,name=<name>]')")
+	cmd.Flags().Bool("wait", false, "Wait for the control plane to be ready")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+	cmd.MarkFlagRequired("name")
+
+	return &cmd
+}
+
+// parseNodePoolFlag parses a node pool in the format
+// 'type=<type>[,count=<n>][,name=<name>]'. Pools without a name are named
+// after their position.
+func parseNodePoolFlag(s string, i int) (client.KubernetesNodePoolSpec, error) {
+	nodePool := client.KubernetesNodePoolSpec{
+		Name:  fmt.Sprintf("pool-%d", i),
+		Count: 1,
+	}
+
+	for k, v := range utils.ParseAttrs(s) {
+		switch k {
+		case "type":
+			nodePool.Type = v
+		case "count":
+			count, err := strconv.Atoi(v)
+			if err != nil || count < 1 {
+				return nodePool, fmt.Errorf("invalid count %q in node pool: %s", v, s)
+			}
+			nodePool.Count = count
+		case "name":
+			nodePool.Name = v
+		default:
+			return nodePool, fmt.Errorf("unknown attribute %q in node pool: %s", k, s)
+		}
+	}
+
+	if nodePool.Type == "" {
+		return nodePool, fmt.Errorf("missing 'type' attribute in node pool: %s", s)
+	}
+
+	return nodePool, nil
+}
+
+func DeleteCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "delete",
+		Short: "delete kubernetes cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(utils.MustGetStringFlag(cmd, "id"))
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.DeleteKubernetesClustersIdWithResponse(cmd.Context(), id, &client.DeleteKubernetesClustersIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusNoContent && res.StatusCode() != http.StatusAccepted {
+				return fmt.Errorf("failed to delete cluster: %s", res.Status())
+			}
+
+			fmt.Printf("Deleting kubernetes cluster with ID: %s\n", id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForDeletion(cmd.Context(), c, projectID, id, timeout)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Cluster ID")
+	cmd.Flags().Bool("wait", false, "Wait for the cluster to be deleted")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+
+	return &cmd
+}
+
+func DescribeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "describe",
+		Short: "describe kubernetes cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(utils.MustGetStringFlag(cmd, "id"))
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.GetKubernetesClustersIdWithResponse(cmd.Context(), id, &client.GetKubernetesClustersIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusOK {
+				return fmt.Errorf("failed to get cluster: %s", res.Status())
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(res.JSON200)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("id", "", "Cluster ID")
+
+	return &cmd
+}
+
+func UpgradeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "upgrade",
+		Short: "upgrade kubernetes cluster",
+		Long:  "Upgrade the control plane and then the node pools of a kubernetes cluster to a newer version.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			version := utils.MustGetStringFlag(cmd, "version")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(utils.MustGetStringFlag(cmd, "id"))
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.PatchKubernetesClustersIdWithResponse(cmd.Context(), id, &client.PatchKubernetesClustersIdParams{
+				XPROJECTID: projectID,
+			}, client.KubernetesClustersPatchRequest{
+				Version: &version,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusOK, http.StatusAccepted:
+			case http.StatusConflict:
+				return fmt.Errorf("cannot upgrade cluster %s: another change is in progress", id)
+			case http.StatusUnprocessableEntity, http.StatusBadRequest:
+				return fmt.Errorf("cannot upgrade cluster %s to version %s: %s", id, version, res.Status())
+			default:
+				return fmt.Errorf("failed to upgrade cluster: %s", res.Status())
+			}
+
+			fmt.Printf("Upgrading kubernetes cluster with ID: %s to version %s\n", id, version)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForCluster(cmd.Context(), c, projectID, id, version, timeout)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Cluster ID")
+	cmd.Flags().String("version", "", "Kubernetes version to upgrade to")
+	cmd.Flags().Bool("wait", false, "Wait for the upgrade to finish")
+	cmd.Flags().Duration("timeout", 60*time.Minute, "Maximum time to wait")
+	cmd.MarkFlagRequired("version")
+
+	return &cmd
+}
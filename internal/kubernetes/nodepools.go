@@ -0,0 +1,460 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/instancetype"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+const nodePoolStateError client.KubernetesNodePoolState = "error"
+
+var taintEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+
+func NodePoolCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:     "nodepools",
+		Aliases: []string{"node-pools"},
+		Short:   "Manage kubernetes node pools",
+		Long:    "Manage the node pools of the kubernetes cluster given by --cluster.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringP("cluster", "c", "", "Name or ID of the kubernetes cluster")
+	cmd.MarkPersistentFlagRequired("cluster")
+
+	cmd.AddCommand(
+		NodePoolListCommand(),
+		NodePoolCreateCommand(),
+		NodePoolScaleCommand(),
+		NodePoolDeleteCommand(),
+	)
+
+	return &cmd
+}
+
+func NodePoolListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list kubernetes node pools",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			cluster, err := lookupCluster(cmd.Context(), c, projectID, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.GetKubernetesClustersIdNodePoolsWithResponse(cmd.Context(), cluster.Id, &client.GetKubernetesClustersIdNodePoolsParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+				return fmt.Errorf("failed to list node pools: %s", res.Status())
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(nodePoolTable(*res.JSON200))
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func NodePoolCreateCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "create <name>",
+		Short: "create kubernetes node pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			nodeType := utils.MustGetStringFlag(cmd, "type")
+			count := utils.MustGetIntFlag(cmd, "count")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			if count < 1 {
+				return fmt.Errorf("invalid count %d: must be at least 1", count)
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			labels, err := utils.ParseLabels(utils.MustGetStringArrayFlag(cmd, "label"))
+			if err != nil {
+				return err
+			}
+
+			taints := []client.KubernetesTaint{}
+			for _, s := range utils.MustGetStringArrayFlag(cmd, "taint") {
+				taint, err := parseTaint(s)
+				if err != nil {
+					return err
+				}
+
+				taints = append(taints, taint)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			if err := instancetype.Validate(cmd.Context(), c, nodeType); err != nil {
+				return err
+			}
+
+			cluster, err := lookupCluster(cmd.Context(), c, projectID, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			nodePool := client.KubernetesNodePoolSpec{
+				Name:  args[0],
+				Type:  nodeType,
+				Count: count,
+			}
+			if len(labels) != 0 {
+				nodePool.Labels = &labels
+			}
+			if len(taints) != 0 {
+				nodePool.Taints = &taints
+			}
+
+			res, err := c.PostKubernetesClustersIdNodePoolsWithResponse(cmd.Context(), cluster.Id, &client.PostKubernetesClustersIdNodePoolsParams{
+				XPROJECTID: projectID,
+			}, nodePool)
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() == http.StatusConflict {
+				return fmt.Errorf("node pool %s already exists in kubernetes cluster %s", args[0], cluster.Name)
+			}
+
+			if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+				return fmt.Errorf("failed to create node pool: %s", res.Status())
+			}
+
+			id := res.JSON201.Id
+			fmt.Printf("Creating node pool %s with ID: %s\n", args[0], id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForNodePool(cmd.Context(), c, projectID, cluster.Id, id, count, timeout)
+		},
+	}
+
+	cmd.Flags().String("type", "", "Instance type of the nodes")
+	cmd.Flags().Int("count", 1, "Number of nodes")
+	cmd.Flags().StringArray("label", []string{}, "Kubernetes label for the nodes (in the format 'key=value')")
+	cmd.Flags().StringArray("taint", []string{}, "Kubernetes taint for the nodes (in the format 'key[=value]:effect')")
+	cmd.Flags().Bool("wait", false, "Wait for the nodes to be ready")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+	cmd.MarkFlagRequired("type")
+	cmd.RegisterFlagCompletionFunc("type", instancetype.Complete)
+
+	return &cmd
+}
+
+func NodePoolScaleCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "scale <node-pool>",
+		Short: "scale kubernetes node pool",
+		Long:  "Change the number of nodes in the node pool given by name or ID.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			count := utils.MustGetIntFlag(cmd, "count")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			if count < 0 {
+				return fmt.Errorf("invalid count %d: must not be negative", count)
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			cluster, err := lookupCluster(cmd.Context(), c, projectID, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			nodePool, err := lookupNodePool(cmd.Context(), c, projectID, cluster.Id, args[0])
+			if err != nil {
+				return err
+			}
+
+			res, err := c.PatchKubernetesClustersIdNodePoolsNodePoolIdWithResponse(cmd.Context(), cluster.Id, nodePool.Id, &client.PatchKubernetesClustersIdNodePoolsNodePoolIdParams{
+				XPROJECTID: projectID,
+			}, client.KubernetesNodePoolPatchRequest{
+				Count: &count,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusOK, http.StatusAccepted:
+			case http.StatusConflict:
+				return fmt.Errorf("cannot scale node pool %s: another change is in progress", nodePool.Name)
+			default:
+				return fmt.Errorf("failed to scale node pool: %s", res.Status())
+			}
+
+			fmt.Printf("Scaling node pool %s from %d to %d nodes\n", nodePool.Name, nodePool.Count, count)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForNodePool(cmd.Context(), c, projectID, cluster.Id, nodePool.Id, count, timeout)
+		},
+	}
+
+	cmd.Flags().Int("count", 0, "Number of nodes")
+	cmd.Flags().Bool("wait", false, "Wait for the nodes to be ready")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+	cmd.MarkFlagRequired("count")
+
+	return &cmd
+}
+
+func NodePoolDeleteCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "delete <node-pool>",
+		Short: "delete kubernetes node pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			cluster, err := lookupCluster(cmd.Context(), c, projectID, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			nodePool, err := lookupNodePool(cmd.Context(), c, projectID, cluster.Id, args[0])
+			if err != nil {
+				return err
+			}
+
+			res, err := c.DeleteKubernetesClustersIdNodePoolsNodePoolIdWithResponse(cmd.Context(), cluster.Id, nodePool.Id, &client.DeleteKubernetesClustersIdNodePoolsNodePoolIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusNoContent && res.StatusCode() != http.StatusAccepted {
+				return fmt.Errorf("failed to delete node pool: %s", res.Status())
+			}
+
+			fmt.Printf("Deleting node pool with ID: %s\n", nodePool.Id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForNodePoolDeletion(cmd.Context(), c, projectID, cluster.Id, nodePool.Id, timeout)
+		},
+	}
+
+	cmd.Flags().Bool("wait", false, "Wait for the node pool to be deleted")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+
+	return &cmd
+}
+
+// lookupNodePool finds a node pool of the cluster by name or ID.
+func lookupNodePool(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, clusterID uuid.UUID, ref string) (*client.KubernetesNodePool, error) {
+	res, err := c.GetKubernetesClustersIdNodePoolsWithResponse(ctx, clusterID, &client.GetKubernetesClustersIdNodePoolsParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list node pools: %s", res.Status())
+	}
+
+	var found *client.KubernetesNodePool
+	for _, nodePool := range *res.JSON200 {
+		if nodePool.Id.String() == ref {
+			return &nodePool, nil
+		}
+
+		if nodePool.Name == ref {
+			if found != nil {
+				return nil, fmt.Errorf("node pool name %q is ambiguous, use the ID instead", ref)
+			}
+
+			found = &nodePool
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("node pool not found: %s", ref)
+	}
+
+	return found, nil
+}
+
+// parseTaint parses a taint in the format 'key[=value]:effect', as taken by
+// kubectl taint.
+func parseTaint(s string) (client.KubernetesTaint, error) {
+	taint := client.KubernetesTaint{}
+
+	kv, effect, found := strings.Cut(s, ":")
+	if !found {
+		return taint, fmt.Errorf("invalid taint %q: must be in the format 'key[=value]:effect'", s)
+	}
+
+	if !slices.Contains(taintEffects, effect) {
+		return taint, fmt.Errorf("invalid taint effect %q: must be one of %s", effect, strings.Join(taintEffects, ", "))
+	}
+
+	taint.Key, taint.Value, _ = strings.Cut(kv, "=")
+	if taint.Key == "" {
+		return taint, fmt.Errorf("invalid taint %q: missing key", s)
+	}
+	taint.Effect = effect
+
+	return taint, nil
+}
+
+type nodePoolTable []client.KubernetesNodePool
+
+func (t nodePoolTable) Header() []string {
+	return []string{"NAME", "ID", "TYPE", "READY", "STATE", "LABELS", "TAINTS"}
+}
+
+func (t nodePoolTable) Rows() [][]string {
+	rows := [][]string{}
+	for _, nodePool := range t {
+		labels := ""
+		if nodePool.Labels != nil {
+			labels = utils.FormatLabels(*nodePool.Labels)
+		}
+
+		taints := []string{}
+		if nodePool.Taints != nil {
+			for _, taint := range *nodePool.Taints {
+				s := taint.Key
+				if taint.Value != "" {
+					s += "=" + taint.Value
+				}
+				taints = append(taints, s+":"+taint.Effect)
+			}
+		}
+
+		rows = append(rows, []string{
+			nodePool.Name,
+			nodePool.Id.String(),
+			nodePool.Type,
+			fmt.Sprintf("%d/%d", nodePool.ReadyCount, nodePool.Count),
+			string(nodePool.State),
+			labels,
+			strings.Join(taints, ","),
+		})
+	}
+
+	return rows
+}
@@ -0,0 +1,45 @@
+package kubernetes
+
+import (
+	"testing"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+)
+
+func TestParseTaint(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    client.KubernetesTaint
+		wantErr bool
+	}{
+		{name: "key and value", in: "gpu=true:NoSchedule", want: client.KubernetesTaint{Key: "gpu", Value: "true", Effect: "NoSchedule"}},
+		{name: "key only", in: "dedicated:NoExecute", want: client.KubernetesTaint{Key: "dedicated", Effect: "NoExecute"}},
+		{name: "value containing =", in: "team=a=b:PreferNoSchedule", want: client.KubernetesTaint{Key: "team", Value: "a=b", Effect: "PreferNoSchedule"}},
+		{name: "missing effect", in: "gpu=true", wantErr: true},
+		{name: "unknown effect", in: "gpu=true:NoSchedul", wantErr: true},
+		{name: "effect is case-sensitive", in: "gpu=true:noschedule", wantErr: true},
+		{name: "empty key", in: "=true:NoSchedule", wantErr: true},
+		{name: "missing key", in: ":NoSchedule", wantErr: true},
+		{name: "empty", in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTaint(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseTaint(%q) = %+v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTaint(%q): %v", tt.in, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseTaint(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
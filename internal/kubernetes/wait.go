@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/wait"
+	"github.com/google/uuid"
+)
+
+const (
+	stateRunning client.KubernetesClusterState = "running"
+	stateError   client.KubernetesClusterState = "error"
+)
+
+// waitForCluster waits for the cluster to be running and, if version is not
+// empty, to run that version.
+func waitForCluster(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, version string, timeout time.Duration) error {
+	fmt.Printf("Waiting for kubernetes cluster %s to be %s\n", id, stateRunning)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("kubernetes cluster %s to be %s", id, stateRunning), func(ctx context.Context) (bool, error) {
+		res, err := c.GetKubernetesClustersIdWithResponse(ctx, id, &client.GetKubernetesClustersIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return false, fmt.Errorf("failed to get cluster: %s", res.Status())
+		}
+
+		if res.JSON200.State == stateError {
+			return false, fmt.Errorf("kubernetes cluster %s is %s", id, res.JSON200.State)
+		}
+
+		return res.JSON200.State == stateRunning && (version == "" || res.JSON200.Version == version), nil
+	})
+}
+
+// waitForDeletion waits until the cluster no longer exists.
+func waitForDeletion(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, timeout time.Duration) error {
+	fmt.Printf("Waiting for kubernetes cluster %s to be deleted\n", id)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("kubernetes cluster %s to be deleted", id), func(ctx context.Context) (bool, error) {
+		res, err := c.GetKubernetesClustersIdWithResponse(ctx, id, &client.GetKubernetesClustersIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		switch res.StatusCode() {
+		case http.StatusNotFound:
+			return true, nil
+		case http.StatusOK:
+			return false, nil
+		default:
+			return false, fmt.Errorf("failed to get cluster: %s", res.Status())
+		}
+	})
+}
+
+// waitForNodePool waits until count nodes of the node pool are ready.
+func waitForNodePool(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, clusterID uuid.UUID, id uuid.UUID, count int, timeout time.Duration) error {
+	fmt.Printf("Waiting for %d nodes of node pool %s to be ready\n", count, id)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("node pool %s to be ready", id), func(ctx context.Context) (bool, error) {
+		res, err := c.GetKubernetesClustersIdNodePoolsNodePoolIdWithResponse(ctx, clusterID, id, &client.GetKubernetesClustersIdNodePoolsNodePoolIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return false, fmt.Errorf("failed to get node pool: %s", res.Status())
+		}
+
+		if res.JSON200.State == nodePoolStateError {
+			return false, fmt.Errorf("node pool %s is %s", id, res.JSON200.State)
+		}
+
+		return res.JSON200.Count == count && res.JSON200.ReadyCount == count, nil
+	})
+}
+
+// waitForNodePoolDeletion waits until the node pool no longer exists.
+func waitForNodePoolDeletion(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, clusterID uuid.UUID, id uuid.UUID, timeout time.Duration) error {
+	fmt.Printf("Waiting for node pool %s to be deleted\n", id)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("node pool %s to be deleted", id), func(ctx context.Context) (bool, error) {
+		res, err := c.GetKubernetesClustersIdNodePoolsNodePoolIdWithResponse(ctx, clusterID, id, &client.GetKubernetesClustersIdNodePoolsNodePoolIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		switch res.StatusCode() {
+		case http.StatusNotFound:
+			return true, nil
+		case http.StatusOK:
+			return false, nil
+		default:
+			return false, fmt.Errorf("failed to get node pool: %s", res.Status())
+		}
+	})
+}
@@ -0,0 +1,362 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+)
+
+// roles are the roles a member can be given, from most to least access.
+var roles = []string{"admin", "member", "viewer"}
+
+func MemberCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "members",
+		Short: "Manage who can access a project",
+		Long: "Manage who can access a project. Members are identified by email and have one of the roles " +
+			strings.Join(roles, ", ") + ".",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringP("project", "P", "", "Project ID")
+
+	cmd.AddCommand(
+		MemberListCommand(),
+		MemberAddCommand(),
+		MemberRemoveCommand(),
+		MemberSetRoleCommand(),
+	)
+
+	return cmd
+}
+
+func MemberListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the members of a project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			members, err := listMembers(cmd.Context(), c, projectID)
+			if err != nil {
+				return err
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(memberTable(members))
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+}
+
+func MemberAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <email>",
+		Short: "Add a member to a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			email, err := parseEmail(args[0])
+			if err != nil {
+				return err
+			}
+
+			role, err := parseRole(utils.MustGetStringFlag(cmd, "role"))
+			if err != nil {
+				return err
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.PostProjectsIdMembersWithResponse(cmd.Context(), projectID, &client.PostProjectsIdMembersParams{}, client.ProjectMembersPostRequest{
+				Email: email,
+				Role:  role,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusCreated:
+			case http.StatusConflict:
+				return fmt.Errorf("%s is already a member of project %s", email, projectID)
+			case http.StatusForbidden:
+				return fmt.Errorf("not allowed to manage members of project %s: %s", projectID, res.Status())
+			default:
+				return fmt.Errorf("failed to add member: %s", res.Status())
+			}
+
+			fmt.Printf("Added %s to project %s as %s\n", email, projectID, role)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("role", "member", "Role of the member ("+strings.Join(roles, ", ")+")")
+	cmd.RegisterFlagCompletionFunc("role", completeRole)
+
+	return cmd
+}
+
+func MemberRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <email>",
+		Short: "Remove a member from a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			member, err := lookupMember(cmd.Context(), c, projectID, args[0])
+			if err != nil {
+				return err
+			}
+
+			res, err := c.DeleteProjectsIdMembersUserIdWithResponse(cmd.Context(), projectID, member.UserId, &client.DeleteProjectsIdMembersUserIdParams{})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusNoContent:
+			case http.StatusConflict:
+				return fmt.Errorf("cannot remove %s: a project must keep at least one admin", member.Email)
+			case http.StatusForbidden:
+				return fmt.Errorf("not allowed to manage members of project %s: %s", projectID, res.Status())
+			default:
+				return fmt.Errorf("failed to remove member: %s", res.Status())
+			}
+
+			fmt.Printf("Removed %s from project %s\n", member.Email, projectID)
+
+			return nil
+		},
+	}
+}
+
+func MemberSetRoleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "set-role <email> <role>",
+		Short:             "Change the role of a project member",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSetRoleArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+
+			role, err := parseRole(args[1])
+			if err != nil {
+				return err
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			member, err := lookupMember(cmd.Context(), c, projectID, args[0])
+			if err != nil {
+				return err
+			}
+
+			if member.Role == role {
+				fmt.Printf("%s is already %s in project %s\n", member.Email, role, projectID)
+				return nil
+			}
+
+			res, err := c.PatchProjectsIdMembersUserIdWithResponse(cmd.Context(), projectID, member.UserId, &client.PatchProjectsIdMembersUserIdParams{}, client.ProjectMembersPatchRequest{
+				Role: role,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusOK:
+			case http.StatusConflict:
+				return fmt.Errorf("cannot change the role of %s: a project must keep at least one admin", member.Email)
+			case http.StatusForbidden:
+				return fmt.Errorf("not allowed to manage members of project %s: %s", projectID, res.Status())
+			default:
+				return fmt.Errorf("failed to set role: %s", res.Status())
+			}
+
+			fmt.Printf("Changed role of %s in project %s from %s to %s\n", member.Email, projectID, member.Role, role)
+
+			return nil
+		},
+	}
+}
+
+func listMembers(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID) ([]client.ProjectMember, error) {
+	res, err := c.GetProjectsIdMembersWithResponse(ctx, projectID, &client.GetProjectsIdMembersParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list members: %s", res.Status())
+	}
+
+	return *res.JSON200, nil
+}
+
+// lookupMember finds a member of the project by email. Emails are compared
+// case-insensitively.
+func lookupMember(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, email string) (*client.ProjectMember, error) {
+	members, err := listMembers(ctx, c, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		if strings.EqualFold(member.Email, email) {
+			return &member, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not a member of project %s", email, projectID)
+}
+
+// parseEmail checks that s is a bare email address.
+func parseEmail(s string) (string, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return "", fmt.Errorf("invalid email address: %s", s)
+	}
+
+	return addr.Address, nil
+}
+
+func parseRole(s string) (client.ProjectMemberRole, error) {
+	role := strings.ToLower(s)
+	if !slices.Contains(roles, role) {
+		return "", fmt.Errorf("invalid role %q: must be one of %s", s, strings.Join(roles, ", "))
+	}
+
+	return client.ProjectMemberRole(role), nil
+}
+
+func completeRole(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return roles, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeSetRoleArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		return roles, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+type memberTable []client.ProjectMember
+
+func (t memberTable) Header() []string {
+	return []string{"EMAIL", "NAME", "ROLE", "ADDED"}
+}
+
+func (t memberTable) Rows() [][]string {
+	rows := [][]string{}
+	for _, member := range t {
+		name := ""
+		if member.Name != nil {
+			name = *member.Name
+		}
+
+		rows = append(rows, []string{member.Email, name, string(member.Role), member.AddedAt.Format("2006-01-02 15:04")})
+	}
+
+	return rows
+}
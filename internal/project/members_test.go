@@ -0,0 +1,79 @@
+package project
+
+import (
+	"testing"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+)
+
+func TestParseEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "plain", in: "alice@example.com"},
+		{name: "subaddress and subdomain", in: "alice+ml@sub.example.com"},
+		{name: "display name", in: "Alice <alice@example.com>", wantErr: true},
+		{name: "leading space", in: " alice@example.com", wantErr: true},
+		{name: "missing domain", in: "alice", wantErr: true},
+		{name: "empty domain", in: "alice@", wantErr: true},
+		{name: "empty", in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEmail(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseEmail(%q) = %q, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEmail(%q): %v", tt.in, err)
+			}
+
+			if got != tt.in {
+				t.Errorf("parseEmail(%q) = %q, want it unchanged", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    client.ProjectMemberRole
+		wantErr bool
+	}{
+		{name: "admin", in: "admin", want: "admin"},
+		{name: "member", in: "member", want: "member"},
+		{name: "viewer", in: "viewer", want: "viewer"},
+		{name: "capitalized", in: "Admin", want: "admin"},
+		{name: "upper case", in: "VIEWER", want: "viewer"},
+		{name: "unknown role", in: "owner", wantErr: true},
+		{name: "leading space", in: " admin", wantErr: true},
+		{name: "empty", in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRole(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseRole(%q) = %q, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRole(%q): %v", tt.in, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseRole(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package project
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
 
 	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
@@ -27,6 +28,9 @@ func Command() *cobra.Command {
 		DeleteCommand(),
 		ListCommand(),
 		DescribeCommand(),
+		UpdateCommand(),
+		SSHKeyCommand(),
+		MemberCommand(),
 	)
 
 	return cmd
@@ -39,6 +43,12 @@ func CreateCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			url := utils.MustGetStringFlag(cmd, "url")
 			name := utils.MustGetStringFlag(cmd, "name")
+			description := utils.MustGetStringFlag(cmd, "description")
+
+			labels, err := utils.ParseLabels(utils.MustGetStringArrayFlag(cmd, "label"))
+			if err != nil {
+				return err
+			}
 
 			token, err := auth.Login(cmd)
 			if err != nil {
@@ -54,9 +64,17 @@ func CreateCommand() *cobra.Command {
 				return err
 			}
 
-			res, err := c.PostProjectsWithResponse(cmd.Context(), &client.PostProjectsParams{}, client.ProjectsPostRequest{
+			project := client.ProjectsPostRequest{
 				Name: name,
-			})
+			}
+			if description != "" {
+				project.Description = &description
+			}
+			if len(labels) != 0 {
+				project.Labels = &labels
+			}
+
+			res, err := c.PostProjectsWithResponse(cmd.Context(), &client.PostProjectsParams{}, project)
 			if err != nil {
 				return err
 			}
@@ -70,6 +88,8 @@ func CreateCommand() *cobra.Command {
 	}
 
 	cmd.Flags().String("name", "", "Name of the project")
+	cmd.Flags().String("description", "", "Description of the project")
+	cmd.Flags().StringArray("label", []string{}, "Label for the project (in the format 'key=value')")
 
 	return cmd
 }
@@ -201,7 +221,7 @@ func DescribeCommand() *cobra.Command {
 				return err
 			}
 
-			if res.StatusCode() != http.StatusOK {
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
 				return fmt.Errorf("failed to get project: %s", res.Status())
 			}
 
@@ -211,7 +231,7 @@ func DescribeCommand() *cobra.Command {
 				return err
 			}
 
-			b, err := m.Marshal(res.JSON200)
+			b, err := m.Marshal(projectDetails(*res.JSON200))
 			if err != nil {
 				return err
 			}
@@ -226,3 +246,140 @@ func DescribeCommand() *cobra.Command {
 
 	return cmd
 }
+
+func UpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update <id>",
+		Short: "Update the name, description or labels of a project",
+		Long: `Update the name, description or labels of a project.
+
+The API replaces the labels of a project as a whole, so --label and
+--remove-label read the current labels, apply the changes and write them all
+back. The API has no conditional update, so if someone else changes the labels
+in between, their change is lost. Avoid updating the labels of the same project
+concurrently.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			removeLabels := utils.MustGetStringArrayFlag(cmd, "remove-label")
+
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			labels, err := utils.ParseLabels(utils.MustGetStringArrayFlag(cmd, "label"))
+			if err != nil {
+				return err
+			}
+
+			update := client.ProjectsPatchRequest{}
+			if cmd.Flags().Changed("name") {
+				name := utils.MustGetStringFlag(cmd, "name")
+				if name == "" {
+					return fmt.Errorf("project name cannot be empty")
+				}
+				update.Name = &name
+			}
+			if cmd.Flags().Changed("description") {
+				description := utils.MustGetStringFlag(cmd, "description")
+				update.Description = &description
+			}
+
+			if update.Name == nil && update.Description == nil && len(labels) == 0 && len(removeLabels) == 0 {
+				return fmt.Errorf("nothing to update, use --name, --description, --label or --remove-label")
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			if len(labels) != 0 || len(removeLabels) != 0 {
+				// Labels are replaced as a whole, so merge the changes into the
+				// current ones. This races with concurrent label updates, see
+				// the command help.
+				res, err := c.GetProjectsIdWithResponse(cmd.Context(), id, &client.GetProjectsIdParams{})
+				if err != nil {
+					return err
+				}
+
+				if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+					return fmt.Errorf("failed to get project: %s", res.Status())
+				}
+
+				merged := map[string]string{}
+				if res.JSON200.Labels != nil {
+					maps.Copy(merged, *res.JSON200.Labels)
+				}
+				for _, k := range removeLabels {
+					delete(merged, k)
+				}
+				maps.Copy(merged, labels)
+
+				update.Labels = &merged
+			}
+
+			res, err := c.PatchProjectsIdWithResponse(cmd.Context(), id, &client.PatchProjectsIdParams{}, update)
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() == http.StatusConflict && update.Name != nil {
+				return fmt.Errorf("a project named %q already exists", *update.Name)
+			}
+
+			if res.StatusCode() != http.StatusOK {
+				return fmt.Errorf("failed to update project: %s", res.Status())
+			}
+
+			fmt.Printf("Updated project with ID: %s\n", id)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("name", "", "New name of the project")
+	cmd.Flags().String("description", "", "New description of the project")
+	cmd.Flags().StringArray("label", []string{}, "Label to add or change (in the format 'key=value')")
+	cmd.Flags().StringArray("remove-label", []string{}, "Key of a label to remove")
+
+	return cmd
+}
+
+// projectDetails is a project with its metadata laid out for describe.
+type projectDetails client.Project
+
+func (p projectDetails) Header() []string {
+	return []string{"FIELD", "VALUE"}
+}
+
+func (p projectDetails) Rows() [][]string {
+	description := ""
+	if p.Description != nil {
+		description = *p.Description
+	}
+
+	labels := ""
+	if p.Labels != nil {
+		labels = utils.FormatLabels(*p.Labels)
+	}
+
+	return [][]string{
+		{"ID", p.Id.String()},
+		{"NAME", p.Name},
+		{"DESCRIPTION", description},
+		{"LABELS", labels},
+		{"CREATED", p.CreatedAt.Format("2006-01-02 15:04")},
+	}
+}
@@ -0,0 +1,178 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fluidstackio/fluidctl/internal/config"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/sshkey"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func SSHKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssh-keys",
+		Short: "Manage local SSH keys added to the instances you create in a project",
+		Long: "Manage SSH keys added to the instances you create in a project. Keys are stored only in the " +
+			"local fluidctl config (~/.fluidstack/config.yaml), not in the project, so other members of the " +
+			"project do not see them and each member manages their own. They are injected into the " +
+			"cloud-config generated by 'instances create' unless --no-project-keys is set, and are not " +
+			"added to slurm clusters.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringP("project", "P", "", "Project ID")
+
+	cmd.AddCommand(
+		SSHKeyAddCommand(),
+		SSHKeyListCommand(),
+		SSHKeyRemoveCommand(),
+	)
+
+	return cmd
+}
+
+func SSHKeyAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add an SSH key to the local config of a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			keyFile := utils.MustGetStringFlag(cmd, "file")
+			key := utils.MustGetStringFlag(cmd, "key")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			var keys []string
+			switch {
+			case keyFile != "" && key != "":
+				return fmt.Errorf("cannot specify both file and key")
+			case keyFile != "":
+				keys, err = sshkey.ReadFile(keyFile)
+				if err != nil {
+					return fmt.Errorf("failed to read ssh public-key file: %w", err)
+				}
+			case key != "":
+				keys, err = sshkey.Parse([]byte(key))
+				if err != nil {
+					return fmt.Errorf("invalid ssh public-key: %w", err)
+				}
+			default:
+				return fmt.Errorf("either file or key must be specified")
+			}
+
+			if len(keys) != 1 {
+				return fmt.Errorf("expected exactly one ssh public-key, found %d", len(keys))
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			p := cfg.Project(projectID)
+			if slices.ContainsFunc(p.SSHKeys, func(k config.SSHKey) bool { return k.Name == name }) {
+				return fmt.Errorf("ssh key %q already exists in project %s", name, projectID)
+			}
+
+			p.SSHKeys = append(p.SSHKeys, config.SSHKey{Name: name, Key: keys[0]})
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Added ssh key %q to project %s\n", name, projectID)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("file", "", "Path to SSH public key")
+	cmd.Flags().String("key", "", "SSH public key")
+
+	return cmd
+}
+
+func SSHKeyListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the SSH keys in the local config of a project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			keys := cfg.Project(projectID).SSHKeys
+			if keys == nil {
+				keys = []config.SSHKey{}
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(keys)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+}
+
+func SSHKeyRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an SSH key from the local config of a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			p := cfg.Project(projectID)
+			i := slices.IndexFunc(p.SSHKeys, func(k config.SSHKey) bool { return k.Name == name })
+			if i < 0 {
+				return fmt.Errorf("ssh key %q not found in project %s", name, projectID)
+			}
+
+			p.SSHKeys = slices.Delete(p.SSHKeys, i, i+1)
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed ssh key %q from project %s\n", name, projectID)
+
+			return nil
+		},
+	}
+}
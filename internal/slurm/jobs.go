@@ -0,0 +1,321 @@
+package slurm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// squeueFormat lists the fields of Job. The name is last since it is the only
+// field that may contain the separator.
+const squeueFormat = "%i|%u|%P|%T|%M|%D|%R|%j"
+
+var scontrolKeyRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9_/:]*$`)
+
+type Job struct {
+	ID        string `json:"id" yaml:"id"`
+	Name      string `json:"name" yaml:"name"`
+	User      string `json:"user" yaml:"user"`
+	Partition string `json:"partition" yaml:"partition"`
+	State     string `json:"state" yaml:"state"`
+	Time      string `json:"time" yaml:"time"`
+	Nodes     string `json:"nodes" yaml:"nodes"`
+	NodeList  string `json:"nodelist" yaml:"nodelist"`
+}
+
+func JobCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "jobs",
+		Short: "Manage slurm jobs",
+		Long:  "Manage slurm jobs. Commands run over SSH on the login node of the cluster given by --cluster.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringP("cluster", "c", "", "Name or ID of the slurm cluster")
+	addSSHFlags(cmd.PersistentFlags())
+
+	cmd.AddCommand(
+		JobSubmitCommand(),
+		JobListCommand(),
+		JobDescribeCommand(),
+		JobCancelCommand(),
+		JobLogsCommand(),
+	)
+
+	return &cmd
+}
+
+func JobSubmitCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "submit <script> [-- sbatch options]",
+		Short: "submit slurm job",
+		Long:  "Submit a local batch script with sbatch. Options after -- are passed to sbatch, e.g.\n\n  fluidctl slurm jobs submit train.sh --cluster research -- --nodes 2 --gres gpu:8\n\nThe script is copied to a temporary file on the login node and submitted from the home directory there, so relative paths in the script and in --output or --chdir resolve against the remote home directory.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			script, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read script: %w", err)
+			}
+			defer script.Close()
+
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			out, err := node.output(cmd.Context(), submitCommand(filepath.Base(args[0]), args[1:]), script)
+			if err != nil {
+				return err
+			}
+
+			// --parsable prints "<id>" or "<id>;<cluster>".
+			id, _, _ := strings.Cut(strings.TrimSpace(string(out)), ";")
+			fmt.Printf("Submitted job with ID: %s\n", id)
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+// submitCommand returns the remote command submitting the script read from
+// stdin. The script is written to a file under its own name rather than piped
+// to sbatch, so the job gets the script name and a script path. sbatch keeps
+// its own copy of the script, so the file is removed right after submitting.
+func submitCommand(name string, sbatchArgs []string) string {
+	command := `dir=$(mktemp -d) && cat > "$dir"/` + quote(name) + " && sbatch --parsable"
+	for _, arg := range sbatchArgs {
+		command += " " + quote(arg)
+	}
+	command += ` "$dir"/` + quote(name) + `; status=$?; rm -rf "$dir"; exit $status`
+
+	return command
+}
+
+func JobListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list slurm jobs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user := utils.MustGetStringFlag(cmd, "user")
+			states := utils.MustGetStringFlag(cmd, "state")
+
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			command := "squeue --noheader --format " + quote(squeueFormat)
+			if user != "" {
+				command += " --user " + quote(user)
+			}
+			if states != "" {
+				command += " --states " + quote(states)
+			}
+
+			out, err := node.output(cmd.Context(), command, nil)
+			if err != nil {
+				return err
+			}
+
+			jobs := []Job{}
+			for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+				if line == "" {
+					continue
+				}
+
+				fields := strings.SplitN(line, "|", 8)
+				if len(fields) != 8 {
+					return fmt.Errorf("unexpected squeue output: %s", line)
+				}
+
+				jobs = append(jobs, Job{
+					ID:        fields[0],
+					User:      fields[1],
+					Partition: fields[2],
+					State:     fields[3],
+					Time:      fields[4],
+					Nodes:     fields[5],
+					NodeList:  fields[6],
+					Name:      fields[7],
+				})
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(jobs)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("user", "", "Only show jobs of this user")
+	cmd.Flags().String("state", "", "Only show jobs in these states (e.g. running,pending)")
+
+	return &cmd
+}
+
+func JobDescribeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "describe <job-id>",
+		Short: "describe slurm job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			job, err := showJob(cmd.Context(), node, args[0])
+			if err != nil {
+				return err
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(job)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func JobCancelCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "cancel <job-id>...",
+		Short: "cancel slurm jobs",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			command := "scancel"
+			for _, id := range args {
+				command += " " + quote(id)
+			}
+
+			if _, err := node.output(cmd.Context(), command, nil); err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				fmt.Printf("Cancelling job with ID: %s\n", id)
+			}
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func JobLogsCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "logs <job-id>",
+		Short: "print slurm job output",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			follow := utils.MustGetBoolFlag(cmd, "follow")
+			stderr := utils.MustGetBoolFlag(cmd, "stderr")
+
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			job, err := showJob(cmd.Context(), node, args[0])
+			if err != nil {
+				return err
+			}
+
+			key := "StdOut"
+			if stderr {
+				key = "StdErr"
+			}
+
+			path := job[key]
+			if path == "" {
+				return fmt.Errorf("job %s has no %s file", args[0], key)
+			}
+
+			command := "tail -n +1 "
+			if follow {
+				command += "-F "
+			}
+			command += quote(path)
+
+			return node.stream(cmd.Context(), command)
+		},
+	}
+
+	cmd.Flags().BoolP("follow", "f", false, "Follow the output")
+	cmd.Flags().Bool("stderr", false, "Print the error output instead, if the job writes it to a separate file")
+
+	return &cmd
+}
+
+// showJob returns the fields of 'scontrol show job'.
+func showJob(ctx context.Context, node *loginNode, id string) (map[string]string, error) {
+	out, err := node.output(ctx, "scontrol show job --oneliner "+quote(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseScontrol(string(out)), nil
+}
+
+// parseScontrol parses the Key=Value pairs printed by scontrol --oneliner.
+// Values may contain spaces, so a word that does not start with a key
+// continues the previous value.
+func parseScontrol(s string) map[string]string {
+	fields := map[string]string{}
+
+	key := ""
+	for _, word := range strings.Fields(s) {
+		k, v, found := strings.Cut(word, "=")
+		if !found || !scontrolKeyRegexp.MatchString(k) {
+			if key != "" {
+				fields[key] += " " + word
+			}
+
+			continue
+		}
+
+		key = k
+		fields[key] = v
+	}
+
+	return fields
+}
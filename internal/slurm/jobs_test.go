@@ -0,0 +1,135 @@
+package slurm
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubmitCommand(t *testing.T) {
+	// A fake sbatch that prints its arguments and the script it was given.
+	bin := t.TempDir()
+	sbatch := "#!/bin/sh\nfor arg; do echo \"arg: $arg\"; done\nfor arg; do script=$arg; done\nbasename \"$script\"\ncat \"$script\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "sbatch"), []byte(sbatch), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		script     string
+		sbatchArgs []string
+		want       string
+	}{
+		{
+			name:   "no options",
+			script: "train.sh",
+			want:   "arg: --parsable\narg: ",
+		},
+		{
+			name:       "options are passed as they are",
+			script:     "train.sh",
+			sbatchArgs: []string{"--nodes", "2", "--job-name=it's mine", "--output=logs/%j.out"},
+			want:       "arg: --parsable\narg: --nodes\narg: 2\narg: --job-name=it's mine\narg: --output=logs/%j.out\narg: ",
+		},
+		{
+			name:   "script name with spaces",
+			script: "my job.sh",
+			want:   "arg: --parsable\narg: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "#!/bin/bash\n#SBATCH --nodes=1\necho hello\n"
+
+			sh := exec.Command("sh", "-c", submitCommand(tt.script, tt.sbatchArgs))
+			sh.Env = append(os.Environ(), "PATH="+bin+":"+os.Getenv("PATH"))
+			sh.Stdin = strings.NewReader(content)
+			out, err := sh.Output()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := string(out)
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("output does not start with %q:\n%s", tt.want, got)
+			}
+			if !strings.HasSuffix(got, "\n"+tt.script+"\n"+content) {
+				t.Errorf("script %q was not submitted:\n%s", tt.script, got)
+			}
+		})
+	}
+}
+
+func TestSubmitCommandExitStatus(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "sbatch"), []byte("#!/bin/sh\necho 'sbatch: error: invalid partition' >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	sh := exec.Command("sh", "-c", submitCommand("train.sh", nil))
+	sh.Env = append(os.Environ(), "PATH="+bin+":"+os.Getenv("PATH"))
+	sh.Stdin = strings.NewReader("#!/bin/bash\n")
+	if err := sh.Run(); err == nil {
+		t.Error("expected the sbatch exit status to be returned")
+	}
+}
+
+func TestParseScontrol(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{
+			name: "empty",
+			want: map[string]string{},
+		},
+		{
+			name: "simple values",
+			in:   "JobId=42 JobName=train UserId=alice(1000) JobState=RUNNING Partition=gpu NumNodes=2",
+			want: map[string]string{"JobId": "42", "JobName": "train", "UserId": "alice(1000)", "JobState": "RUNNING", "Partition": "gpu", "NumNodes": "2"},
+		},
+		{
+			name: "empty value",
+			in:   "JobId=42 Comment= Reservation=(null)",
+			want: map[string]string{"JobId": "42", "Comment": "", "Reservation": "(null)"},
+		},
+		{
+			name: "value containing =",
+			in:   "JobId=42 TRES=cpu=64,mem=512G,node=2,billing=64,gres/gpu=16 Command=/home/alice/train.sh",
+			want: map[string]string{"JobId": "42", "TRES": "cpu=64,mem=512G,node=2,billing=64,gres/gpu=16", "Command": "/home/alice/train.sh"},
+		},
+		{
+			name: "command with arguments",
+			in:   "JobId=42 Command=/home/alice/train.sh --epochs 10 --lr=0.01 WorkDir=/home/alice",
+			want: map[string]string{"JobId": "42", "Command": "/home/alice/train.sh --epochs 10 --lr=0.01", "WorkDir": "/home/alice"},
+		},
+		{
+			name: "reason with spaces",
+			in:   "JobId=42 JobState=PENDING Reason=ReqNodeNotAvail,_Reserved_for_maintenance Dependency=(null)",
+			want: map[string]string{"JobId": "42", "JobState": "PENDING", "Reason": "ReqNodeNotAvail,_Reserved_for_maintenance", "Dependency": "(null)"},
+		},
+		{
+			name: "node reason with spaces",
+			in:   "NodeName=gpu-001 State=IDLE+DRAIN Reason=bad gpu replace [root@2025-01-01T12:00:00] Partitions=gpu",
+			want: map[string]string{"NodeName": "gpu-001", "State": "IDLE+DRAIN", "Reason": "bad gpu replace [root@2025-01-01T12:00:00]", "Partitions": "gpu"},
+		},
+		{
+			name: "trailing newline",
+			in:   "JobId=42 JobName=train\n",
+			want: map[string]string{"JobId": "42", "JobName": "train"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseScontrol(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseScontrol() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package slurm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// shortStates maps node base states to the names sinfo uses.
+var shortStates = map[string]string{
+	"ALLOCATED": "alloc",
+	"MIXED":     "mix",
+	"DRAIN":     "drain",
+	"DRAINING":  "drng",
+	"DRAINED":   "drain",
+}
+
+type Node struct {
+	Name       string `json:"name" yaml:"name"`
+	State      string `json:"state" yaml:"state"`
+	Partitions string `json:"partitions" yaml:"partitions"`
+	CPUs       string `json:"cpus" yaml:"cpus"`
+	GRES       string `json:"gres" yaml:"gres"`
+	GRESUsed   string `json:"gres_used" yaml:"gres_used"`
+	Reason     string `json:"reason" yaml:"reason"`
+}
+
+type Partition struct {
+	Name  string `json:"name" yaml:"name"`
+	Nodes int    `json:"nodes" yaml:"nodes"`
+	Idle  int    `json:"idle" yaml:"idle"`
+	Alloc int    `json:"alloc" yaml:"alloc"`
+	Mix   int    `json:"mix" yaml:"mix"`
+	Drain int    `json:"drain" yaml:"drain"`
+	Down  int    `json:"down" yaml:"down"`
+	GRES  string `json:"gres" yaml:"gres"`
+}
+
+// scontrolNode is a node as printed by 'scontrol show nodes --json'.
+type scontrolNode struct {
+	Name       string    `json:"name"`
+	State      stateList `json:"state"`
+	StateFlags []string  `json:"state_flags"`
+	Partitions []string  `json:"partitions"`
+	Cpus       int       `json:"cpus"`
+	AllocCpus  int       `json:"alloc_cpus"`
+	Gres       string    `json:"gres"`
+	GresUsed   string    `json:"gres_used"`
+	Reason     string    `json:"reason"`
+}
+
+// stateList is a node state, which older Slurm versions print as a single
+// string such as "idle" with the flags in state_flags, and newer ones as a
+// list such as ["IDLE", "DRAIN"].
+type stateList []string
+
+func (s *stateList) UnmarshalJSON(b []byte) error {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*s = list
+		return nil
+	}
+
+	var state string
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+
+	*s = strings.Split(state, "+")
+
+	return nil
+}
+
+// String returns the state in sinfo's notation, e.g. "idle+drain".
+func (s stateList) String() string {
+	states := []string{}
+	for _, state := range s {
+		state = strings.ToUpper(strings.TrimRight(state, "*~#!%$@^-"))
+		if short, ok := shortStates[state]; ok {
+			states = append(states, short)
+		} else {
+			states = append(states, strings.ToLower(state))
+		}
+	}
+
+	return strings.Join(states, "+")
+}
+
+func (s stateList) has(state string) bool {
+	return slices.Contains(strings.Split(s.String(), "+"), state)
+}
+
+func NodeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "nodes",
+		Short: "Inspect and manage slurm nodes",
+		Long:  "Inspect and manage slurm nodes. Commands run over SSH on the login node of the cluster given by --cluster.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringP("cluster", "c", "", "Name or ID of the slurm cluster")
+	addSSHFlags(cmd.PersistentFlags())
+
+	cmd.AddCommand(
+		NodeListCommand(),
+		NodeDrainCommand(),
+		NodeResumeCommand(),
+	)
+
+	return &cmd
+}
+
+func NodeListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list slurm nodes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			partition := utils.MustGetStringFlag(cmd, "partition")
+			state := strings.ToLower(utils.MustGetStringFlag(cmd, "state"))
+
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			scontrolNodes, err := showNodes(cmd.Context(), node)
+			if err != nil {
+				return err
+			}
+
+			nodes := []Node{}
+			for _, n := range scontrolNodes {
+				if partition != "" && !slices.Contains(n.Partitions, partition) {
+					continue
+				}
+
+				if state != "" && !n.State.has(state) {
+					continue
+				}
+
+				nodes = append(nodes, Node{
+					Name:       n.Name,
+					State:      n.State.String(),
+					Partitions: strings.Join(n.Partitions, ","),
+					CPUs:       fmt.Sprintf("%d/%d", n.AllocCpus, n.Cpus),
+					GRES:       n.Gres,
+					GRESUsed:   n.GresUsed,
+					Reason:     n.Reason,
+				})
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(nodes)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("partition", "", "Only show nodes in this partition")
+	cmd.Flags().String("state", "", "Only show nodes in this state (e.g. idle, alloc, drain)")
+
+	return &cmd
+}
+
+func NodeDrainCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "drain <node>...",
+		Short: "drain slurm nodes",
+		Long:  "Stop scheduling new jobs on the nodes. Running jobs are allowed to finish.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reason := utils.MustGetStringFlag(cmd, "reason")
+
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			command := "sudo -n scontrol update NodeName=" + quote(strings.Join(args, ",")) + " State=DRAIN Reason=" + quote(reason)
+			if _, err := node.output(cmd.Context(), command, nil); err != nil {
+				return err
+			}
+
+			for _, name := range args {
+				fmt.Printf("Draining node %s\n", name)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("reason", "", "Reason for draining the nodes")
+	cmd.MarkFlagRequired("reason")
+
+	return &cmd
+}
+
+func NodeResumeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "resume <node>...",
+		Short: "resume slurm nodes",
+		Long:  "Return drained or down nodes to service.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			command := "sudo -n scontrol update NodeName=" + quote(strings.Join(args, ",")) + " State=RESUME"
+			if _, err := node.output(cmd.Context(), command, nil); err != nil {
+				return err
+			}
+
+			for _, name := range args {
+				fmt.Printf("Resuming node %s\n", name)
+			}
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func PartitionCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "partitions",
+		Short: "Inspect slurm partitions",
+		Long:  "Inspect slurm partitions. Commands run over SSH on the login node of the cluster given by --cluster.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.PersistentFlags().StringP("cluster", "c", "", "Name or ID of the slurm cluster")
+	addSSHFlags(cmd.PersistentFlags())
+
+	cmd.AddCommand(
+		PartitionListCommand(),
+	)
+
+	return &cmd
+}
+
+func PartitionListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "list slurm partitions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			node, err := connect(cmd, utils.MustGetStringFlag(cmd, "cluster"))
+			if err != nil {
+				return err
+			}
+
+			scontrolNodes, err := showNodes(cmd.Context(), node)
+			if err != nil {
+				return err
+			}
+
+			partitions := partitionSummary(scontrolNodes)
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(partitions)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	return &cmd
+}
+
+func showNodes(ctx context.Context, node *loginNode) ([]scontrolNode, error) {
+	out, err := node.output(ctx, "scontrol show nodes --json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNodes(out)
+}
+
+// parseNodes parses the output of 'scontrol show nodes --json'.
+func parseNodes(b []byte) ([]scontrolNode, error) {
+	var res struct {
+		Nodes []scontrolNode `json:"nodes"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse scontrol output: %w", err)
+	}
+
+	for i := range res.Nodes {
+		n := &res.Nodes[i]
+		n.State = append(n.State, n.StateFlags...)
+	}
+
+	return res.Nodes, nil
+}
+
+// partitionSummary counts the nodes of each partition by state, in the order
+// the partitions are first seen.
+func partitionSummary(nodes []scontrolNode) []Partition {
+	partitions := []Partition{}
+	for _, n := range nodes {
+		for _, name := range n.Partitions {
+			i := slices.IndexFunc(partitions, func(p Partition) bool { return p.Name == name })
+			if i < 0 {
+				partitions = append(partitions, Partition{Name: name})
+				i = len(partitions) - 1
+			}
+
+			p := &partitions[i]
+			p.Nodes++
+
+			switch {
+			case n.State.has("down"):
+				p.Down++
+			case n.State.has("drain"), n.State.has("drng"):
+				p.Drain++
+			case n.State.has("alloc"):
+				p.Alloc++
+			case n.State.has("mix"):
+				p.Mix++
+			case n.State.has("idle"):
+				p.Idle++
+			}
+
+			if n.Gres != "" && !slices.Contains(strings.Split(p.GRES, ","), n.Gres) {
+				p.GRES = strings.TrimPrefix(p.GRES+","+n.Gres, ",")
+			}
+		}
+	}
+
+	return partitions
+}
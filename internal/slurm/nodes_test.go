@@ -0,0 +1,113 @@
+package slurm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStateListUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    stateList
+		str     string
+		wantErr bool
+	}{
+		{name: "string", in: `"idle"`, want: stateList{"idle"}, str: "idle"},
+		{name: "string with flags", in: `"IDLE+DRAIN"`, want: stateList{"IDLE", "DRAIN"}, str: "idle+drain"},
+		{name: "list", in: `["MIXED"]`, want: stateList{"MIXED"}, str: "mix"},
+		{name: "list with flags", in: `["IDLE","DRAIN"]`, want: stateList{"IDLE", "DRAIN"}, str: "idle+drain"},
+		{name: "allocated and draining", in: `["ALLOCATED","DRAIN"]`, want: stateList{"ALLOCATED", "DRAIN"}, str: "alloc+drain"},
+		{name: "number", in: `1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got stateList
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("state = %v, want %v", got, tt.want)
+			}
+			if got.String() != tt.str {
+				t.Errorf("String() = %q, want %q", got.String(), tt.str)
+			}
+		})
+	}
+}
+
+// The fixtures are 'scontrol show nodes --json' output of the same cluster
+// from Slurm 22.05, which prints the state as a string with separate flags,
+// and 23.11, which prints it as a list.
+var nodeFixtures = []string{"nodes-22.05.json", "nodes-23.11.json"}
+
+func TestParseNodes(t *testing.T) {
+	want := map[string]string{
+		"gpu-001": "alloc",
+		"gpu-002": "mix",
+		"gpu-003": "idle+drain",
+		"cpu-001": "down+not_responding",
+	}
+
+	for _, fixture := range nodeFixtures {
+		t.Run(fixture, func(t *testing.T) {
+			nodes := readNodes(t, fixture)
+
+			got := map[string]string{}
+			for _, n := range nodes {
+				got[n.Name] = n.State.String()
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("states = %v, want %v", got, want)
+			}
+
+			n := nodes[2]
+			if n.Reason != "bad gpu" || n.Cpus != 64 || n.AllocCpus != 0 || n.Gres != "gpu:h100:8" || !reflect.DeepEqual(n.Partitions, []string{"gpu", "debug"}) {
+				t.Errorf("unexpected node: %+v", n)
+			}
+		})
+	}
+}
+
+func TestPartitionSummary(t *testing.T) {
+	want := []Partition{
+		{Name: "gpu", Nodes: 3, Alloc: 1, Mix: 1, Drain: 1, GRES: "gpu:h100:8"},
+		{Name: "debug", Nodes: 2, Drain: 1, Down: 1, GRES: "gpu:h100:8"},
+	}
+
+	for _, fixture := range nodeFixtures {
+		t.Run(fixture, func(t *testing.T) {
+			if got := partitionSummary(readNodes(t, fixture)); !reflect.DeepEqual(got, want) {
+				t.Errorf("partitionSummary() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func readNodes(t *testing.T, fixture string) []scontrolNode {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := parseNodes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return nodes
+}
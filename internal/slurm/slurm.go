@@ -3,10 +3,15 @@ package slurm
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
 	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/filesystem"
 	"github.com/fluidstackio/fluidctl/internal/format"
+	images "github.com/fluidstackio/fluidctl/internal/image"
+	"github.com/fluidstackio/fluidctl/internal/instancetype"
+	"github.com/fluidstackio/fluidctl/internal/sshkey"
 	"github.com/fluidstackio/fluidctl/internal/utils"
 	"github.com/google/uuid"
 	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
@@ -26,6 +31,11 @@ func Command() *cobra.Command {
 
 	cmd.AddCommand(
 		ClusterCommand(),
+		JobCommand(),
+		NodeCommand(),
+		PartitionCommand(),
+		SSHCommand(),
+		SSHConfigCommand(),
 	)
 
 	return &cmd
@@ -41,7 +51,11 @@ func ClusterCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		CreateCommand(),
+		DeleteCommand(),
 		ListCommand(),
+		DescribeCommand(),
+		ScaleCommand(),
 	)
 
 	return &cmd
@@ -102,3 +116,352 @@ func ListCommand() *cobra.Command {
 
 	return &cmd
 }
+
+func CreateCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "create",
+		Short: "create slurm cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			name := utils.MustGetStringFlag(cmd, "name")
+			nodeType := utils.MustGetStringFlag(cmd, "node-type")
+			nodeCount := utils.MustGetIntFlag(cmd, "node-count")
+			image := utils.MustGetStringFlag(cmd, "image")
+			loginNodeType := utils.MustGetStringFlag(cmd, "login-node-type")
+			loginNodePublicIP := utils.MustGetBoolFlag(cmd, "login-node-public-ip")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			if nodeCount < 1 {
+				return fmt.Errorf("invalid node count %d: must be at least 1", nodeCount)
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			sshAuthorizedKeys, err := sshkey.FromFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			attachments := []filesystem.Attachment{}
+			for _, fs := range utils.MustGetStringArrayFlag(cmd, "filesystem") {
+				attachment, err := filesystem.ParseAttachment(fs)
+				if err != nil {
+					return err
+				}
+
+				attachments = append(attachments, attachment)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			if err := instancetype.Validate(cmd.Context(), c, nodeType); err != nil {
+				return err
+			}
+
+			if err := instancetype.Validate(cmd.Context(), c, loginNodeType); err != nil {
+				return err
+			}
+
+			if err := filesystem.ResolveAttachments(cmd.Context(), c, projectID, attachments); err != nil {
+				return err
+			}
+
+			if err := filesystem.ValidateAttachments(attachments); err != nil {
+				return err
+			}
+
+			cluster := client.SlurmClustersPostRequest{
+				Name:      name,
+				NodeType:  nodeType,
+				NodeCount: nodeCount,
+				LoginNode: &client.SlurmLoginNodeSpec{
+					Type:     loginNodeType,
+					PublicIp: &loginNodePublicIP,
+				},
+			}
+
+			if len(sshAuthorizedKeys) != 0 {
+				cluster.LoginNode.SshAuthorizedKeys = &sshAuthorizedKeys
+			}
+
+			if len(attachments) != 0 {
+				filesystems := []client.SlurmClusterFilesystem{}
+				for _, attachment := range attachments {
+					fs := client.SlurmClusterFilesystem{
+						Id:       attachment.ID,
+						ReadOnly: &attachment.ReadOnly,
+					}
+					if attachment.MountPath != "" {
+						fs.MountPath = &attachment.MountPath
+					}
+
+					filesystems = append(filesystems, fs)
+				}
+
+				cluster.Filesystems = &filesystems
+			}
+
+			if image != "" {
+				imageURL, err := images.Resolve(cmd.Context(), c, image)
+				if err != nil {
+					return err
+				}
+
+				cluster.Image = &imageURL
+			}
+
+			res, err := c.PostSlurmClustersWithResponse(cmd.Context(), &client.PostSlurmClustersParams{
+				XPROJECTID: projectID,
+			}, cluster)
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusCreated || res.JSON201 == nil {
+				return fmt.Errorf("failed to create cluster: %s", res.Status())
+			}
+
+			id := res.JSON201.Id
+			fmt.Printf("Creating slurm cluster %s with ID: %s\n", name, id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForCluster(cmd.Context(), c, projectID, id, stateRunning, -1, timeout)
+		},
+	}
+
+	cmd.Flags().String("name", "", "Name of the cluster")
+	cmd.Flags().String("node-type", "", "Instance type of the compute nodes")
+	cmd.Flags().Int("node-count", 1, "Number of compute nodes")
+	cmd.Flags().String("image", "", "Image name, alias or URL of the nodes")
+	cmd.Flags().String("login-node-type", "cpu.2x", "Instance type of the login node")
+	cmd.Flags().Bool("login-node-public-ip", true, "Give the login node a public IP address")
+	cmd.Flags().StringArray("filesystem", []string{}, "Shared filesystem to mount on all nodes (in the format 'id=<UUID>|name=<name>[,mount=<path>][,readonly=true]')")
+	cmd.Flags().Bool("wait", false, "Wait for the cluster to be running")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+	sshkey.AddFlags(cmd.Flags())
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("node-type")
+	cmd.RegisterFlagCompletionFunc("node-type", instancetype.Complete)
+	cmd.RegisterFlagCompletionFunc("login-node-type", instancetype.Complete)
+	cmd.RegisterFlagCompletionFunc("image", images.Complete)
+
+	return &cmd
+}
+
+func DeleteCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "delete",
+		Short: "delete slurm cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(utils.MustGetStringFlag(cmd, "id"))
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.DeleteSlurmClustersIdWithResponse(cmd.Context(), id, &client.DeleteSlurmClustersIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusNoContent && res.StatusCode() != http.StatusAccepted {
+				return fmt.Errorf("failed to delete cluster: %s", res.Status())
+			}
+
+			fmt.Printf("Deleting slurm cluster with ID: %s\n", id)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForDeletion(cmd.Context(), c, projectID, id, timeout)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Cluster ID")
+	cmd.Flags().Bool("wait", false, "Wait for the cluster to be deleted")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+
+	return &cmd
+}
+
+func DescribeCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "describe",
+		Short: "describe slurm cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(utils.MustGetStringFlag(cmd, "id"))
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.GetSlurmClustersIdWithResponse(cmd.Context(), id, &client.GetSlurmClustersIdParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusOK {
+				return fmt.Errorf("failed to get cluster: %s", res.Status())
+			}
+
+			f := utils.MustGetStringFlag(cmd, "format")
+			m, err := format.NewMarshaller(format.Format(f))
+			if err != nil {
+				return err
+			}
+
+			b, err := m.Marshal(res.JSON200)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(b))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("id", "", "Cluster ID")
+
+	return &cmd
+}
+
+func ScaleCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "scale",
+		Short: "scale slurm cluster",
+		Long:  "Change the number of compute nodes of a slurm cluster. Jobs running on removed nodes are requeued.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			nodeCount := utils.MustGetIntFlag(cmd, "node-count")
+			wait := utils.MustGetBoolFlag(cmd, "wait")
+			timeout := utils.MustGetDurationFlag(cmd, "timeout")
+
+			if nodeCount < 1 {
+				return fmt.Errorf("invalid node count %d: must be at least 1", nodeCount)
+			}
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			id, err := uuid.Parse(utils.MustGetStringFlag(cmd, "id"))
+			if err != nil {
+				return fmt.Errorf("invalid UUID: %w", err)
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.PatchSlurmClustersIdWithResponse(cmd.Context(), id, &client.PatchSlurmClustersIdParams{
+				XPROJECTID: projectID,
+			}, client.SlurmClustersPatchRequest{
+				NodeCount: &nodeCount,
+			})
+			if err != nil {
+				return err
+			}
+
+			switch res.StatusCode() {
+			case http.StatusOK, http.StatusAccepted:
+			case http.StatusConflict:
+				return fmt.Errorf("cannot scale cluster %s: another change is in progress", id)
+			default:
+				return fmt.Errorf("failed to scale cluster: %s", res.Status())
+			}
+
+			fmt.Printf("Scaling slurm cluster with ID: %s to %d nodes\n", id, nodeCount)
+
+			if !wait {
+				return nil
+			}
+
+			return waitForCluster(cmd.Context(), c, projectID, id, stateRunning, nodeCount, timeout)
+		},
+	}
+
+	cmd.Flags().String("id", "", "Cluster ID")
+	cmd.Flags().Int("node-count", 0, "Number of compute nodes")
+	cmd.Flags().Bool("wait", false, "Wait for the cluster to be running with the new node count")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait")
+	cmd.MarkFlagRequired("node-count")
+
+	return &cmd
+}
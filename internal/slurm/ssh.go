@@ -0,0 +1,343 @@
+package slurm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/mitchellh/go-homedir"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// loginNode runs commands on the login node of a cluster over SSH.
+type loginNode struct {
+	cluster client.SlurmCluster
+	host    string
+	options []string
+}
+
+// connect looks up the cluster given by name or ID and returns its login
+// node.
+func connect(cmd *cobra.Command, ref string) (*loginNode, error) {
+	url := utils.MustGetStringFlag(cmd, "url")
+	sshUser := utils.MustGetStringFlag(cmd, "ssh-user")
+	identity := utils.MustGetStringFlag(cmd, "identity")
+
+	if ref == "" {
+		return nil, fmt.Errorf("no slurm cluster given, use --cluster")
+	}
+
+	projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	token, err := auth.Login(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+	bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bearer auth: %w", err)
+	}
+
+	c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := lookupCluster(cmd.Context(), c, projectID, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if cluster.LoginNode == nil || cluster.LoginNode.Ip == nil {
+		return nil, fmt.Errorf("slurm cluster %s has no login node address, it is %s", cluster.Name, cluster.State)
+	}
+
+	options := []string{"-o", "StrictHostKeyChecking=accept-new"}
+	if identity != "" {
+		options = append(options, "-i", identity)
+	}
+
+	return &loginNode{
+		cluster: *cluster,
+		host:    sshUser + "@" + *cluster.LoginNode.Ip,
+		options: options,
+	}, nil
+}
+
+// lookupCluster finds a cluster by name or ID.
+func lookupCluster(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, ref string) (*client.SlurmCluster, error) {
+	res, err := c.GetSlurmClustersWithResponse(ctx, &client.GetSlurmClustersParams{
+		XPROJECTID: projectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list clusters: %s", res.Status())
+	}
+
+	var found *client.SlurmCluster
+	for _, cluster := range *res.JSON200 {
+		if cluster.Id.String() == ref {
+			return &cluster, nil
+		}
+
+		if cluster.Name == ref {
+			if found != nil {
+				return nil, fmt.Errorf("slurm cluster name %q is ambiguous, use the ID instead", ref)
+			}
+
+			found = &cluster
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("slurm cluster not found: %s", ref)
+	}
+
+	return found, nil
+}
+
+// command returns ssh running command on the login node.
+func (n *loginNode) command(ctx context.Context, command string, interactive bool) *exec.Cmd {
+	args := append([]string{}, n.options...)
+	if interactive {
+		args = append(args, "-t")
+	} else {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	args = append(args, n.host)
+	if command != "" {
+		args = append(args, "--", command)
+	}
+
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// output runs command on the login node and returns its stdout. The remote
+// stderr is included in the error if the command fails.
+func (n *loginNode) output(ctx context.Context, command string, stdin io.Reader) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	ssh := n.command(ctx, command, false)
+	ssh.Stdin = stdin
+	ssh.Stdout = &stdout
+	ssh.Stderr = &stderr
+
+	if err := ssh.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", n.cluster.Name, msg)
+		}
+
+		return nil, fmt.Errorf("%s: ssh failed: %w", n.cluster.Name, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// stream runs command on the login node with its output going to ours.
+func (n *loginNode) stream(ctx context.Context, command string) error {
+	ssh := n.command(ctx, command, false)
+	ssh.Stdout = os.Stdout
+	ssh.Stderr = os.Stderr
+
+	return ssh.Run()
+}
+
+// quote quotes s for the remote shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// addSSHFlags registers the flags used to reach the login node of a cluster.
+func addSSHFlags(flags *pflag.FlagSet) {
+	flags.String("ssh-user", "ubuntu", "User for SSH connections to the login node")
+	flags.StringP("identity", "i", "", "SSH private key for the login node")
+}
+
+func SSHCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "ssh <cluster> [-- command]",
+		Short: "ssh to the login node of a slurm cluster",
+		Long:  "Open a shell, or run a command, on the login node of the slurm cluster given by name or ID.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			node, err := connect(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			command := ""
+			for _, arg := range args[1:] {
+				command += " " + quote(arg)
+			}
+
+			ssh := node.command(cmd.Context(), strings.TrimSpace(command), true)
+			ssh.Stdin = os.Stdin
+			ssh.Stdout = os.Stdout
+			ssh.Stderr = os.Stderr
+
+			if err := ssh.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					// ssh has already shown the remote error, only pass on
+					// the exit status.
+					cmd.SilenceErrors = true
+					cmd.SilenceUsage = true
+					return &utils.ExitError{Code: exitErr.ExitCode()}
+				}
+
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	addSSHFlags(cmd.Flags())
+
+	return &cmd
+}
+
+func SSHConfigCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "ssh-config",
+		Short: "generate ssh config for slurm clusters",
+		Long: `Print an ssh config entry for the login node of every slurm cluster, so
+that 'ssh fluidstack-<cluster>' works with ssh, scp, rsync and editors. Write
+it to a file and include it from ~/.ssh/config:
+
+  fluidctl slurm ssh-config -P <project> --output ~/.ssh/fluidstack.config
+  echo 'Include ~/.ssh/fluidstack.config' >> ~/.ssh/config`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			sshUser := utils.MustGetStringFlag(cmd, "ssh-user")
+			identity := utils.MustGetStringFlag(cmd, "identity")
+			output := utils.MustGetStringFlag(cmd, "output")
+
+			projectID, err := uuid.Parse(utils.MustGetStringFlag(cmd, "project"))
+			if err != nil {
+				return fmt.Errorf("invalid project ID: %w", err)
+			}
+
+			if !validSSHConfigValue(sshUser) {
+				return fmt.Errorf("invalid ssh user %q", sshUser)
+			}
+
+			if identity != "" {
+				identity, err = filepath.Abs(identity)
+				if err != nil {
+					return err
+				}
+
+				if strings.ContainsAny(identity, "\"\r\n") {
+					return fmt.Errorf("invalid identity file %q: path cannot be written to an ssh config", identity)
+				}
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			res, err := c.GetSlurmClustersWithResponse(cmd.Context(), &client.GetSlurmClustersParams{
+				XPROJECTID: projectID,
+			})
+			if err != nil {
+				return err
+			}
+
+			if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+				return fmt.Errorf("failed to list clusters: %s", res.Status())
+			}
+
+			var b strings.Builder
+			fmt.Fprintf(&b, "# Generated by fluidctl slurm ssh-config for project %s\n", projectID)
+
+			for _, cluster := range *res.JSON200 {
+				if cluster.LoginNode == nil || cluster.LoginNode.Ip == nil {
+					fmt.Fprintf(os.Stderr, "Skipping slurm cluster %s: no login node address\n", cluster.Name)
+					continue
+				}
+
+				if !validSSHConfigValue(cluster.Name) || strings.ContainsAny(cluster.Name, "*?!") {
+					fmt.Fprintf(os.Stderr, "Skipping slurm cluster %q: name cannot be used as an ssh host alias\n", cluster.Name)
+					continue
+				}
+
+				fmt.Fprintf(&b, "\nHost fluidstack-%s\n", cluster.Name)
+				fmt.Fprintf(&b, "  HostName %s\n", *cluster.LoginNode.Ip)
+				fmt.Fprintf(&b, "  User %s\n", sshUser)
+				if identity != "" {
+					fmt.Fprintf(&b, "  IdentityFile %s\n", quoteSSHConfigValue(identity))
+				}
+				fmt.Fprintf(&b, "  StrictHostKeyChecking accept-new\n")
+			}
+
+			if output == "" {
+				fmt.Print(b.String())
+				return nil
+			}
+
+			output, err = homedir.Expand(output)
+			if err != nil {
+				return fmt.Errorf("failed to expand path: %w", err)
+			}
+
+			if err := os.WriteFile(output, []byte(b.String()), 0600); err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote ssh config to %s\n", output)
+
+			return nil
+		},
+	}
+
+	addSSHFlags(cmd.Flags())
+	cmd.Flags().StringP("output", "o", "", "Write the config to this file instead of stdout")
+
+	return &cmd
+}
+
+// validSSHConfigValue reports whether s can be written as a single unquoted
+// ssh_config argument.
+func validSSHConfigValue(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\r\n\"'#\\")
+}
+
+// quoteSSHConfigValue double quotes s if it contains spaces, which ssh_config
+// would otherwise split into several arguments.
+func quoteSSHConfigValue(s string) string {
+	if !strings.ContainsAny(s, " \t") {
+		return s
+	}
+
+	return `"` + s + `"`
+}
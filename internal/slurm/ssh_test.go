@@ -0,0 +1,41 @@
+package slurm
+
+import "testing"
+
+func TestValidSSHConfigValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{in: "training", want: true},
+		{in: "team-a.prod_1", want: true},
+		{in: "", want: false},
+		{in: "my cluster", want: false},
+		{in: "a\tb", want: false},
+		{in: "a\nHost *", want: false},
+		{in: `a"b`, want: false},
+		{in: "a#b", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := validSSHConfigValue(tt.in); got != tt.want {
+			t.Errorf("validSSHConfigValue(%q) = %t, want %t", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteSSHConfigValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "/home/me/.ssh/id_ed25519", want: "/home/me/.ssh/id_ed25519"},
+		{in: "/home/me/my keys/id", want: `"/home/me/my keys/id"`},
+	}
+
+	for _, tt := range tests {
+		if got := quoteSSHConfigValue(tt.in); got != tt.want {
+			t.Errorf("quoteSSHConfigValue(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
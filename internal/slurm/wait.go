@@ -0,0 +1,65 @@
+package slurm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/wait"
+	"github.com/google/uuid"
+)
+
+const (
+	stateRunning client.SlurmClusterState = "running"
+	stateError   client.SlurmClusterState = "error"
+)
+
+// waitForCluster waits for the cluster to reach the target state and, if
+// nodeCount is not negative, to have that many nodes.
+func waitForCluster(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, target client.SlurmClusterState, nodeCount int, timeout time.Duration) error {
+	fmt.Printf("Waiting for slurm cluster %s to be %s\n", id, target)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("slurm cluster %s to be %s", id, target), func(ctx context.Context) (bool, error) {
+		res, err := c.GetSlurmClustersIdWithResponse(ctx, id, &client.GetSlurmClustersIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return false, fmt.Errorf("failed to get cluster: %s", res.Status())
+		}
+
+		if res.JSON200.State == stateError {
+			return false, fmt.Errorf("slurm cluster %s is %s", id, res.JSON200.State)
+		}
+
+		return res.JSON200.State == target && (nodeCount < 0 || res.JSON200.NodeCount == nodeCount), nil
+	})
+}
+
+// waitForDeletion waits until the cluster no longer exists.
+func waitForDeletion(ctx context.Context, c *client.ClientWithResponses, projectID uuid.UUID, id uuid.UUID, timeout time.Duration) error {
+	fmt.Printf("Waiting for slurm cluster %s to be deleted\n", id)
+
+	return wait.Poll(ctx, timeout, fmt.Sprintf("slurm cluster %s to be deleted", id), func(ctx context.Context) (bool, error) {
+		res, err := c.GetSlurmClustersIdWithResponse(ctx, id, &client.GetSlurmClustersIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		switch res.StatusCode() {
+		case http.StatusNotFound:
+			return true, nil
+		case http.StatusOK:
+			return false, nil
+		default:
+			return false, fmt.Errorf("failed to get cluster: %s", res.Status())
+		}
+	})
+}
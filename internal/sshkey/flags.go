@@ -0,0 +1,98 @@
+package sshkey
+
+import (
+	"fmt"
+
+	"github.com/fluidstackio/fluidctl/internal/config"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// AddFlags registers the flags selecting the SSH public keys to authorize.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.StringArray("ssh-authorized-key", []string{}, "Path to SSH public key")
+	flags.Bool("ssh-key-from-agent", false, "Authorize all identities from the SSH agent at SSH_AUTH_SOCK")
+	flags.Bool("ssh-key-default", false, "Authorize the default SSH public key (~/.ssh/id_ed25519.pub, id_ecdsa.pub or id_rsa.pub)")
+	flags.StringArray("ssh-key-url", []string{}, "HTTPS URL of a list of SSH public keys, one per line")
+	flags.StringArray("ssh-key-github", []string{}, "GitHub user whose SSH public keys to authorize")
+}
+
+// FromFlags gathers the public keys from all flags registered by AddFlags.
+// Every key is validated and duplicates are dropped.
+func FromFlags(cmd *cobra.Command) ([]string, error) {
+	keys := []string{}
+
+	for _, path := range utils.MustGetStringArrayFlag(cmd, "ssh-authorized-key") {
+		fileKeys, err := ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssh public-key file: %w", err)
+		}
+
+		keys = append(keys, fileKeys...)
+	}
+
+	if utils.MustGetBoolFlag(cmd, "ssh-key-default") {
+		defaultKeys, err := Default()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default ssh public-key: %w", err)
+		}
+
+		keys = append(keys, defaultKeys...)
+	}
+
+	if utils.MustGetBoolFlag(cmd, "ssh-key-from-agent") {
+		agentKeys, err := FromAgent()
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, agentKeys...)
+	}
+
+	urls := utils.MustGetStringArrayFlag(cmd, "ssh-key-url")
+	for _, user := range utils.MustGetStringArrayFlag(cmd, "ssh-key-github") {
+		urls = append(urls, GitHubURL(user))
+	}
+
+	for _, url := range urls {
+		urlKeys, err := FromURL(cmd.Context(), url)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, urlKeys...)
+	}
+
+	return unique(keys), nil
+}
+
+// WithProjectKeys adds the keys stored for the project by 'projects ssh-keys
+// add' to keys, dropping duplicates. The project keys live in the local config
+// only, so they are not shared with other users of the project.
+func WithProjectKeys(keys []string, projectID uuid.UUID) ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range cfg.Project(projectID).SSHKeys {
+		keys = append(keys, key.Key)
+	}
+
+	return unique(keys), nil
+}
+
+func unique(keys []string) []string {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if !seen[key] {
+			unique = append(unique, key)
+			seen[key] = true
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,193 @@
+package sshkey
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// defaultKeyFiles are tried in order by Default, matching the preference of
+// ssh-keygen.
+var defaultKeyFiles = []string{
+	"~/.ssh/id_ed25519.pub",
+	"~/.ssh/id_ecdsa.pub",
+	"~/.ssh/id_rsa.pub",
+}
+
+// urlClient fetches keys from URLs. Only https is allowed, including for
+// redirects, so the keys cannot be swapped in transit.
+var urlClient = &http.Client{
+	Timeout: 30 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing to follow redirect to %s: only https is allowed", req.URL)
+		}
+
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+
+		return nil
+	},
+}
+
+// maxURLKeysSize limits how much is read from a key URL.
+const maxURLKeysSize = 1 << 20
+
+// Parse validates that every line of data is a well-formed OpenSSH public
+// key and returns the keys. Empty lines and comments are skipped.
+func Parse(data []byte) ([]string, error) {
+	keys := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line)); err != nil {
+			return nil, fmt.Errorf("line %d is not a valid OpenSSH public key: %w", n, err)
+		}
+
+		keys = append(keys, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func ReadFile(path string) ([]string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return keys, nil
+}
+
+// Default returns the first public key found in the default locations.
+func Default() ([]string, error) {
+	for _, path := range defaultKeyFiles {
+		keys, err := ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return keys, nil
+	}
+
+	return nil, fmt.Errorf("no SSH public key found in %s", filepath.Dir(defaultKeyFiles[0]))
+}
+
+// FromAgent returns the public keys of all identities held by the agent at
+// SSH_AUTH_SOCK.
+func FromAgent() ([]string, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("SSH_AUTH_SOCK is not set")
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+	defer conn.Close()
+
+	identities, err := agent.NewClient(conn).List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ssh-agent identities: %w", err)
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("ssh-agent has no identities")
+	}
+
+	keys := []string{}
+	for _, identity := range identities {
+		keys = append(keys, identity.String())
+	}
+
+	return keys, nil
+}
+
+// FromURL fetches a bundle of public keys, one per line, such as
+// https://github.com/<user>.keys. The URL must use https.
+func FromURL(ctx context.Context, rawURL string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid URL %s: only https URLs are allowed", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	res, err := urlClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, res.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxURLKeysSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+
+	if len(data) > maxURLKeysSize {
+		return nil, fmt.Errorf("failed to fetch %s: response is larger than %d bytes", rawURL, maxURLKeysSize)
+	}
+
+	keys, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rawURL, err)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no keys found", rawURL)
+	}
+
+	return keys, nil
+}
+
+func GitHubURL(user string) string {
+	return "https://github.com/" + user + ".keys"
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/google/uuid"
+)
+
+type Kind int
+
+const (
+	Projects Kind = iota
+	Instances
+	Filesystems
+	SlurmClusters
+	KubernetesClusters
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Projects:
+		return "projects"
+	case Instances:
+		return "instances"
+	case Filesystems:
+		return "filesystems"
+	case SlurmClusters:
+		return "slurm clusters"
+	case KubernetesClusters:
+		return "kubernetes clusters"
+	default:
+		return "unknown"
+	}
+}
+
+// Row is a single resource as shown in the UI. Object holds the full API
+// object and is what gets rendered in the describe pane.
+type Row struct {
+	ID      uuid.UUID
+	Name    string
+	State   string
+	Address string
+	Object  any
+}
+
+// Backend is the set of API calls the UI makes. The UI only talks to the
+// Atlas API through this interface so it can be driven by a fake backend on a
+// simulated screen.
+type Backend interface {
+	List(ctx context.Context, kind Kind, projectID uuid.UUID) ([]Row, error)
+	Delete(ctx context.Context, kind Kind, projectID uuid.UUID, id uuid.UUID) error
+}
+
+type atlasBackend struct {
+	c *client.ClientWithResponses
+}
+
+func NewBackend(c *client.ClientWithResponses) Backend {
+	return &atlasBackend{c: c}
+}
+
+func (b *atlasBackend) List(ctx context.Context, kind Kind, projectID uuid.UUID) ([]Row, error) {
+	rows := []Row{}
+
+	switch kind {
+	case Projects:
+		res, err := b.c.GetProjectsWithResponse(ctx, &client.GetProjectsParams{})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to list projects: %s", res.Status())
+		}
+
+		for _, p := range *res.JSON200 {
+			rows = append(rows, Row{ID: p.Id, Name: p.Name, Object: p})
+		}
+	case Instances:
+		res, err := b.c.GetInstancesWithResponse(ctx, &client.GetInstancesParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to list instances: %s", res.Status())
+		}
+
+		for _, i := range *res.JSON200 {
+			row := Row{ID: i.Id, Name: i.Name, State: string(i.State), Object: i}
+			if i.Ip != nil {
+				row.Address = *i.Ip
+			}
+
+			rows = append(rows, row)
+		}
+	case Filesystems:
+		res, err := b.c.GetFilesystemsWithResponse(ctx, &client.GetFilesystemsParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to list filesystems: %s", res.Status())
+		}
+
+		for _, fs := range *res.JSON200 {
+			rows = append(rows, Row{ID: fs.Id, Name: fs.Name, State: string(fs.State), Object: fs})
+		}
+	case SlurmClusters:
+		res, err := b.c.GetSlurmClustersWithResponse(ctx, &client.GetSlurmClustersParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to list clusters: %s", res.Status())
+		}
+
+		for _, c := range *res.JSON200 {
+			row := Row{ID: c.Id, Name: c.Name, State: string(c.State), Object: c}
+			if c.LoginNode != nil && c.LoginNode.Ip != nil {
+				row.Address = *c.LoginNode.Ip
+			}
+
+			rows = append(rows, row)
+		}
+	case KubernetesClusters:
+		res, err := b.c.GetKubernetesClustersWithResponse(ctx, &client.GetKubernetesClustersParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode() != http.StatusOK || res.JSON200 == nil {
+			return nil, fmt.Errorf("failed to list clusters: %s", res.Status())
+		}
+
+		for _, c := range *res.JSON200 {
+			rows = append(rows, Row{ID: c.Id, Name: c.Name, State: string(c.State), Object: c})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported resource: %s", kind)
+	}
+
+	return rows, nil
+}
+
+func (b *atlasBackend) Delete(ctx context.Context, kind Kind, projectID uuid.UUID, id uuid.UUID) error {
+	switch kind {
+	case Instances:
+		res, err := b.c.DeleteInstancesIdWithResponse(ctx, id, &client.DeleteInstancesIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode() != http.StatusNoContent {
+			return fmt.Errorf("failed to delete instance: %s", res.Status())
+		}
+	case Filesystems:
+		res, err := b.c.DeleteFilesystemsIdWithResponse(ctx, id, &client.DeleteFilesystemsIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode() != http.StatusNoContent {
+			return fmt.Errorf("failed to delete filesystem: %s", res.Status())
+		}
+	case SlurmClusters:
+		res, err := b.c.DeleteSlurmClustersIdWithResponse(ctx, id, &client.DeleteSlurmClustersIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode() != http.StatusNoContent && res.StatusCode() != http.StatusAccepted {
+			return fmt.Errorf("failed to delete slurm cluster: %s", res.Status())
+		}
+	case KubernetesClusters:
+		res, err := b.c.DeleteKubernetesClustersIdWithResponse(ctx, id, &client.DeleteKubernetesClustersIdParams{
+			XPROJECTID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode() != http.StatusNoContent && res.StatusCode() != http.StatusAccepted {
+			return fmt.Errorf("failed to delete kubernetes cluster: %s", res.Status())
+		}
+	default:
+		return fmt.Errorf("deleting %s is not supported", kind)
+	}
+
+	return nil
+}
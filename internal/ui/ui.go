@@ -0,0 +1,476 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	client "github.com/fluidstackio/atlas-client-go/v1alpha1"
+	"github.com/fluidstackio/fluidctl/internal/auth"
+	"github.com/fluidstackio/fluidctl/internal/format"
+	"github.com/fluidstackio/fluidctl/internal/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/uuid"
+	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "ui",
+		Short: "Interactive terminal UI",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := utils.MustGetStringFlag(cmd, "url")
+			sshUser := utils.MustGetStringFlag(cmd, "ssh-user")
+			refresh := utils.MustGetDurationFlag(cmd, "refresh")
+
+			if refresh < 0 {
+				return fmt.Errorf("invalid refresh interval %s: must not be negative", refresh)
+			}
+
+			projectID := uuid.Nil
+			if s := utils.MustGetStringFlag(cmd, "project"); s != "" {
+				id, err := uuid.Parse(s)
+				if err != nil {
+					return fmt.Errorf("invalid project ID: %w", err)
+				}
+
+				projectID = id
+			}
+
+			token, err := auth.Login(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			bearerAuth, err := securityprovider.NewSecurityProviderBearerToken(token)
+			if err != nil {
+				return fmt.Errorf("failed to create bearer auth: %w", err)
+			}
+
+			c, err := client.NewClientWithResponses(url+"/api/v1alpha1/", client.WithRequestEditorFn(bearerAuth.Intercept))
+			if err != nil {
+				return err
+			}
+
+			screen, err := tcell.NewScreen()
+			if err != nil {
+				return fmt.Errorf("failed to open terminal: %w", err)
+			}
+
+			app := NewApp(cmd.Context(), NewBackend(c), screen, projectID)
+			app.RefreshInterval = refresh
+			app.SSH = func(address string) error {
+				ssh := exec.Command("ssh", sshUser+"@"+address)
+				ssh.Stdin = os.Stdin
+				ssh.Stdout = os.Stdout
+				ssh.Stderr = os.Stderr
+
+				return ssh.Run()
+			}
+
+			return app.Run()
+		},
+	}
+
+	cmd.Flags().StringP("project", "P", "", "Project ID")
+	cmd.Flags().String("ssh-user", "ubuntu", "User for SSH connections to instances")
+	cmd.Flags().Duration("refresh", 5*time.Second, "Refresh interval (0 disables auto-refresh)")
+
+	return &cmd
+}
+
+const keyHelp = "[1] instances [2] filesystems [3] slurm [4] kubernetes [p] project [enter/d] describe [ctrl-d] delete [s] ssh [r] refresh [q] quit"
+
+// App is the terminal UI. It draws on the given screen, so passing a
+// tcell.SimulationScreen allows driving it without a real terminal.
+type App struct {
+	// RefreshInterval is how often the current view is reloaded. Zero
+	// disables auto-refresh.
+	RefreshInterval time.Duration
+
+	// SSH is called with the address of the selected instance while the UI is
+	// suspended. If nil, the ssh key binding is disabled.
+	SSH func(address string) error
+
+	ctx     context.Context
+	backend Backend
+
+	app     *tview.Application
+	pages   *tview.Pages
+	body    *tview.Flex
+	header  *tview.TextView
+	table   *tview.Table
+	details *tview.TextView
+	status  *tview.TextView
+
+	kind     Kind
+	project  Row
+	rows     []Row
+	describe bool
+}
+
+func NewApp(ctx context.Context, backend Backend, screen tcell.Screen, projectID uuid.UUID) *App {
+	a := &App{
+		RefreshInterval: 5 * time.Second,
+		ctx:             ctx,
+		backend:         backend,
+		app:             tview.NewApplication().SetScreen(screen),
+		pages:           tview.NewPages(),
+		body:            tview.NewFlex(),
+		header:          tview.NewTextView(),
+		table:           tview.NewTable(),
+		details:         tview.NewTextView(),
+		status:          tview.NewTextView(),
+		kind:            Instances,
+		project:         Row{ID: projectID, Name: projectID.String()},
+	}
+
+	a.table.SetSelectable(true, false).SetFixed(1, 0)
+	a.table.SetBorder(true)
+	a.table.SetSelectionChangedFunc(func(row, column int) {
+		a.renderDetails()
+	})
+	a.table.SetSelectedFunc(func(row, column int) {
+		a.describe = !a.describe
+		a.layout()
+	})
+
+	a.details.SetBorder(true).SetTitle(" describe ")
+
+	a.body.AddItem(a.table, 0, 1, true)
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.header, 2, 0, false).
+		AddItem(a.body, 0, 1, true).
+		AddItem(a.status, 1, 0, false)
+
+	a.pages.AddPage("main", root, true, true)
+
+	a.app.SetRoot(a.pages, true)
+	a.app.SetInputCapture(a.handleKey)
+
+	return a
+}
+
+// Run starts the UI and blocks until the user quits.
+func (a *App) Run() error {
+	a.renderHeader()
+
+	if a.project.ID == uuid.Nil {
+		a.showProjects()
+	} else {
+		a.refresh()
+	}
+
+	if a.RefreshInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			ticker := time.NewTicker(a.RefreshInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					a.app.QueueUpdate(a.refresh)
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	return a.app.Run()
+}
+
+// Stop stops the UI, causing Run to return.
+func (a *App) Stop() {
+	a.app.Stop()
+}
+
+func (a *App) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	// Modal pages (confirmations, project picker) handle their own keys.
+	if name, _ := a.pages.GetFrontPage(); name != "main" {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyCtrlD:
+		a.confirmDelete()
+		return nil
+	case tcell.KeyCtrlC:
+		a.app.Stop()
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch event.Rune() {
+	case '1':
+		a.switchKind(Instances)
+	case '2':
+		a.switchKind(Filesystems)
+	case '3':
+		a.switchKind(SlurmClusters)
+	case '4':
+		a.switchKind(KubernetesClusters)
+	case 'p':
+		a.showProjects()
+	case 'd':
+		a.describe = !a.describe
+		a.layout()
+	case 's':
+		a.ssh()
+	case 'r':
+		a.refresh()
+	case 'q':
+		a.app.Stop()
+	default:
+		return event
+	}
+
+	return nil
+}
+
+func (a *App) switchKind(kind Kind) {
+	if kind == a.kind {
+		return
+	}
+
+	a.kind = kind
+	a.rows = nil
+	a.renderHeader()
+	a.renderTable()
+	a.refresh()
+}
+
+// refresh reloads the current view in the background. It must be called from
+// the UI goroutine.
+func (a *App) refresh() {
+	kind, projectID := a.kind, a.project.ID
+	if projectID == uuid.Nil {
+		return
+	}
+
+	go func() {
+		rows, err := a.backend.List(a.ctx, kind, projectID)
+
+		a.app.QueueUpdateDraw(func() {
+			if kind != a.kind || projectID != a.project.ID {
+				return
+			}
+
+			if err != nil {
+				a.setStatus(err.Error())
+				return
+			}
+
+			selected, _ := a.selected()
+
+			a.rows = rows
+			a.setStatus(fmt.Sprintf("updated %s", time.Now().Format(time.TimeOnly)))
+			a.renderTable()
+
+			for i, r := range a.rows {
+				if r.ID == selected.ID {
+					a.table.Select(i+1, 0)
+				}
+			}
+		})
+	}()
+}
+
+func (a *App) selected() (Row, bool) {
+	row, _ := a.table.GetSelection()
+	if row < 1 || row > len(a.rows) {
+		return Row{}, false
+	}
+
+	return a.rows[row-1], true
+}
+
+func (a *App) layout() {
+	a.body.Clear()
+	a.body.AddItem(a.table, 0, 1, true)
+	if a.describe {
+		a.body.AddItem(a.details, 0, 1, false)
+		a.renderDetails()
+	}
+}
+
+func (a *App) renderHeader() {
+	a.header.SetText(fmt.Sprintf("project: %s | %s\n%s", a.project.Name, a.kind, keyHelp))
+	a.table.SetTitle(fmt.Sprintf(" %s ", a.kind))
+}
+
+func (a *App) renderTable() {
+	a.table.Clear()
+
+	headers := []string{"NAME", "STATE", "ID"}
+	if a.kind == Instances || a.kind == SlurmClusters {
+		headers = append(headers, "ADDRESS")
+	}
+
+	for i, h := range headers {
+		a.table.SetCell(0, i, tview.NewTableCell(h).SetSelectable(false).SetTextColor(tcell.ColorYellow))
+	}
+
+	for i, r := range a.rows {
+		cells := []string{r.Name, r.State, r.ID.String()}
+		if a.kind == Instances || a.kind == SlurmClusters {
+			cells = append(cells, r.Address)
+		}
+
+		for j, c := range cells {
+			a.table.SetCell(i+1, j, tview.NewTableCell(c).SetExpansion(1))
+		}
+	}
+
+	a.renderDetails()
+}
+
+func (a *App) renderDetails() {
+	if !a.describe {
+		return
+	}
+
+	a.details.Clear()
+
+	row, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	b, err := (&format.YAMLMarshaller{}).Marshal(row.Object)
+	if err != nil {
+		a.details.SetText(err.Error())
+		return
+	}
+
+	a.details.SetText(string(b)).ScrollToBeginning()
+}
+
+func (a *App) setStatus(s string) {
+	a.status.SetText(s)
+}
+
+func (a *App) showProjects() {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(" projects ")
+
+	dismiss := func() {
+		a.pages.RemovePage("projects")
+		a.app.SetFocus(a.table)
+	}
+
+	list.SetDoneFunc(dismiss)
+	list.AddItem("loading...", "", 0, nil)
+
+	a.pages.AddPage("projects", modal(list, 60, 20), true, true)
+	a.app.SetFocus(list)
+
+	go func() {
+		rows, err := a.backend.List(a.ctx, Projects, uuid.Nil)
+
+		a.app.QueueUpdateDraw(func() {
+			list.Clear()
+
+			if err != nil {
+				a.setStatus(err.Error())
+				dismiss()
+				return
+			}
+
+			for _, p := range rows {
+				list.AddItem(p.Name, p.ID.String(), 0, func() {
+					a.project = p
+					a.rows = nil
+					dismiss()
+					a.renderHeader()
+					a.renderTable()
+					a.refresh()
+				})
+			}
+		})
+	}()
+}
+
+func (a *App) confirmDelete() {
+	row, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	kind, projectID := a.kind, a.project.ID
+
+	dialog := tview.NewModal().
+		SetText(fmt.Sprintf("Delete %s %s (%s)?", kind, row.Name, row.ID)).
+		AddButtons([]string{"Cancel", "Delete"}).
+		SetDoneFunc(func(index int, label string) {
+			a.pages.RemovePage("confirm")
+			a.app.SetFocus(a.table)
+
+			if label != "Delete" {
+				return
+			}
+
+			a.setStatus(fmt.Sprintf("deleting %s...", row.Name))
+
+			go func() {
+				err := a.backend.Delete(a.ctx, kind, projectID, row.ID)
+
+				a.app.QueueUpdateDraw(func() {
+					if err != nil {
+						a.setStatus(err.Error())
+						return
+					}
+
+					a.setStatus(fmt.Sprintf("deleting %s with ID: %s", kind, row.ID))
+					a.refresh()
+				})
+			}()
+		})
+
+	a.pages.AddPage("confirm", dialog, false, true)
+	a.app.SetFocus(dialog)
+}
+
+func (a *App) ssh() {
+	if a.SSH == nil || (a.kind != Instances && a.kind != SlurmClusters) {
+		return
+	}
+
+	row, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	if row.Address == "" {
+		a.setStatus(fmt.Sprintf("%s has no address", row.Name))
+		return
+	}
+
+	var err error
+	a.app.Suspend(func() {
+		err = a.SSH(row.Address)
+	})
+
+	if err != nil {
+		a.setStatus(fmt.Sprintf("ssh: %s", err))
+	}
+}
+
+func modal(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/uuid"
+)
+
+type fakeBackend struct {
+	mu      sync.Mutex
+	rows    map[Kind][]Row
+	deleted []uuid.UUID
+}
+
+func (b *fakeBackend) List(ctx context.Context, kind Kind, projectID uuid.UUID) ([]Row, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rows := []Row{}
+	for _, r := range b.rows[kind] {
+		if !contains(b.deleted, r.ID) {
+			rows = append(rows, r)
+		}
+	}
+
+	return rows, nil
+}
+
+func (b *fakeBackend) Delete(ctx context.Context, kind Kind, projectID uuid.UUID, id uuid.UUID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deleted = append(b.deleted, id)
+
+	return nil
+}
+
+func (b *fakeBackend) isDeleted(id uuid.UUID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return contains(b.deleted, id)
+}
+
+func contains(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordingScreen keeps a copy of the text on the simulation screen each time
+// it is shown, since the cells returned by GetContents are drawn into
+// concurrently.
+type recordingScreen struct {
+	tcell.SimulationScreen
+
+	mu   sync.Mutex
+	text string
+}
+
+func (s *recordingScreen) Show() {
+	s.SimulationScreen.Show()
+	s.record()
+}
+
+func (s *recordingScreen) Sync() {
+	s.SimulationScreen.Sync()
+	s.record()
+}
+
+func (s *recordingScreen) record() {
+	cells, width, _ := s.GetContents()
+
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 && i%width == 0 {
+			b.WriteByte('\n')
+		}
+		b.Write(cell.Bytes)
+	}
+
+	s.mu.Lock()
+	s.text = b.String()
+	s.mu.Unlock()
+}
+
+func (s *recordingScreen) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.text
+}
+
+// startApp runs the UI on a simulated screen until the test ends.
+func startApp(t *testing.T, backend Backend, projectID uuid.UUID, refresh time.Duration) *recordingScreen {
+	t.Helper()
+
+	screen := &recordingScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(160, 30)
+
+	app := NewApp(context.Background(), backend, screen, projectID)
+	app.RefreshInterval = refresh
+
+	done := make(chan error)
+	go func() {
+		done <- app.Run()
+	}()
+
+	t.Cleanup(func() {
+		app.Stop()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+
+	return screen
+}
+
+// waitFor waits until the screen shows (or, if present is false, no longer
+// shows) s.
+func waitFor(t *testing.T, screen *recordingScreen, s string, present bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if strings.Contains(screen.Text(), s) == present {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for %q (present=%t) on screen:\n%s", s, present, screen.Text())
+}
+
+func TestAppSwitchesViews(t *testing.T) {
+	backend := &fakeBackend{rows: map[Kind][]Row{
+		Instances:   {{ID: uuid.New(), Name: "trainer-0", State: "running", Address: "203.0.113.7"}},
+		Filesystems: {{ID: uuid.New(), Name: "datasets", State: "ready"}},
+	}}
+
+	screen := startApp(t, backend, uuid.New(), 0)
+
+	waitFor(t, screen, "trainer-0", true)
+	waitFor(t, screen, "203.0.113.7", true)
+
+	screen.InjectKey(tcell.KeyRune, '2', tcell.ModNone)
+	waitFor(t, screen, "datasets", true)
+	waitFor(t, screen, "trainer-0", false)
+
+	screen.InjectKey(tcell.KeyRune, '1', tcell.ModNone)
+	waitFor(t, screen, "trainer-0", true)
+}
+
+func TestAppDescribe(t *testing.T) {
+	backend := &fakeBackend{rows: map[Kind][]Row{
+		Instances: {{ID: uuid.New(), Name: "trainer-0", State: "running", Object: map[string]string{"image": "ubuntu-24.04"}}},
+	}}
+
+	screen := startApp(t, backend, uuid.New(), 0)
+	waitFor(t, screen, "trainer-0", true)
+
+	screen.InjectKey(tcell.KeyRune, 'd', tcell.ModNone)
+	waitFor(t, screen, "image: ubuntu-24.04", true)
+}
+
+func TestAppDelete(t *testing.T) {
+	id := uuid.New()
+	backend := &fakeBackend{rows: map[Kind][]Row{
+		Instances: {{ID: id, Name: "trainer-0", State: "running"}},
+	}}
+
+	screen := startApp(t, backend, uuid.New(), 0)
+	waitFor(t, screen, "trainer-0", true)
+
+	screen.InjectKey(tcell.KeyCtrlD, 0, tcell.ModNone)
+	waitFor(t, screen, "Delete instances", true)
+
+	// Focus starts on Cancel.
+	screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+
+	waitFor(t, screen, "trainer-0", false)
+	if !backend.isDeleted(id) {
+		t.Errorf("instance %s was not deleted", id)
+	}
+}
+
+func TestAppDeleteCancel(t *testing.T) {
+	id := uuid.New()
+	backend := &fakeBackend{rows: map[Kind][]Row{
+		Instances: {{ID: id, Name: "trainer-0", State: "running"}},
+	}}
+
+	screen := startApp(t, backend, uuid.New(), 0)
+	waitFor(t, screen, "trainer-0", true)
+
+	screen.InjectKey(tcell.KeyCtrlD, 0, tcell.ModNone)
+	waitFor(t, screen, "Delete instances", true)
+
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	waitFor(t, screen, "Delete instances", false)
+
+	if backend.isDeleted(id) {
+		t.Errorf("instance %s was deleted after cancel", id)
+	}
+}
+
+func TestAppProjectPicker(t *testing.T) {
+	projectID := uuid.New()
+	backend := &fakeBackend{rows: map[Kind][]Row{
+		Projects:  {{ID: projectID, Name: "research"}},
+		Instances: {{ID: uuid.New(), Name: "trainer-0", State: "running"}},
+	}}
+
+	screen := startApp(t, backend, uuid.Nil, 0)
+	waitFor(t, screen, "research", true)
+
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	waitFor(t, screen, "project: research", true)
+	waitFor(t, screen, "trainer-0", true)
+}
+
+func TestAppAutoRefresh(t *testing.T) {
+	backend := &fakeBackend{rows: map[Kind][]Row{
+		Instances: {{ID: uuid.New(), Name: "trainer-0", State: "starting"}},
+	}}
+
+	screen := startApp(t, backend, uuid.New(), 50*time.Millisecond)
+	waitFor(t, screen, "starting", true)
+
+	backend.mu.Lock()
+	backend.rows[Instances][0].State = "running"
+	backend.mu.Unlock()
+
+	waitFor(t, screen, "running", true)
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -46,6 +49,14 @@ func MustGetIntFlag(cmd *cobra.Command, name string) int {
 	return value
 }
 
+func MustGetDurationFlag(cmd *cobra.Command, name string) time.Duration {
+	value, err := cmd.Flags().GetDuration(name)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
 func ParseAttrs(s string) map[string]string {
 	attrs := map[string]string{}
 	for _, fs := range strings.Split(s, ",") {
@@ -58,3 +69,40 @@ func ParseAttrs(s string) map[string]string {
 
 	return attrs
 }
+
+// ParseLabels parses labels in the format 'key=value'.
+func ParseLabels(labels []string) (map[string]string, error) {
+	parsed := map[string]string{}
+	for _, s := range labels {
+		k, v, found := strings.Cut(s, "=")
+		if !found || k == "" {
+			return nil, fmt.Errorf("invalid label %q: must be in the format 'key=value'", s)
+		}
+
+		parsed[k] = v
+	}
+
+	return parsed, nil
+}
+
+// FormatLabels returns the labels as 'key=value' pairs sorted by key and
+// separated by commas.
+func FormatLabels(labels map[string]string) string {
+	pairs := []string{}
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// ExitError makes fluidctl exit with Code without printing an error, for
+// commands that pass on the exit status of a command they ran.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", in: nil, want: map[string]string{}},
+		{name: "several", in: []string{"team=ml", "env=prod"}, want: map[string]string{"team": "ml", "env": "prod"}},
+		{name: "empty value", in: []string{"empty="}, want: map[string]string{"empty": ""}},
+		{name: "value containing =", in: []string{"expr=a=b"}, want: map[string]string{"expr": "a=b"}},
+		{name: "last value wins", in: []string{"team=ml", "team=infra"}, want: map[string]string{"team": "infra"}},
+		{name: "missing =", in: []string{"team"}, wantErr: true},
+		{name: "empty key", in: []string{"=ml"}, wantErr: true},
+		{name: "empty label", in: []string{"team=ml", ""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabels(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseLabels(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLabels(%q): %v", tt.in, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLabels(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		in   map[string]string
+		want string
+	}{
+		{name: "none", in: nil, want: ""},
+		{name: "one", in: map[string]string{"team": "ml"}, want: "team=ml"},
+		{name: "sorted by key", in: map[string]string{"team": "ml", "env": "prod", "empty": ""}, want: "empty=,env=prod,team=ml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatLabels(tt.in); got != tt.want {
+				t.Errorf("FormatLabels(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
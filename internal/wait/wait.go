@@ -0,0 +1,45 @@
+// Package wait polls the API until a resource reaches the state a command is
+// waiting for.
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Interval is how often Poll checks. It is shared by all commands so that
+// --wait behaves the same everywhere.
+const Interval = 5 * time.Second
+
+// Poll calls check right away and then every Interval until it reports done,
+// fails or the timeout expires. what describes the awaited condition in the
+// timeout error, e.g. "instance <id> to be running".
+func Poll(ctx context.Context, timeout time.Duration, what string, check func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("timed out waiting for %s", what)
+			}
+
+			return err
+		}
+
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s", what)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		check   func(ctx context.Context) (bool, error)
+		timeout time.Duration
+		want    string
+	}{
+		{
+			name:    "done",
+			check:   func(ctx context.Context) (bool, error) { return true, nil },
+			timeout: time.Second,
+		},
+		{
+			name:    "error",
+			check:   func(ctx context.Context) (bool, error) { return false, errFailed },
+			timeout: time.Second,
+			want:    "failed",
+		},
+		{
+			name:    "timeout",
+			check:   func(ctx context.Context) (bool, error) { return false, nil },
+			timeout: 10 * time.Millisecond,
+			want:    "timed out waiting for thing to be ready",
+		},
+		{
+			name: "error after timeout",
+			check: func(ctx context.Context) (bool, error) {
+				<-ctx.Done()
+				return false, ctx.Err()
+			},
+			timeout: 10 * time.Millisecond,
+			want:    "timed out waiting for thing to be ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Poll(context.Background(), tt.timeout, "thing to be ready", tt.check)
+
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+
+			if got != tt.want {
+				t.Errorf("Poll() error = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}